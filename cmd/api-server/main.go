@@ -3,13 +3,27 @@ package main
 import (
 	"context"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/SwissOpenEM/globus"
 	"github.com/SwissOpenEM/globus-transfer-service/internal/api"
 	"github.com/SwissOpenEM/globus-transfer-service/internal/config"
+	"github.com/SwissOpenEM/globus-transfer-service/internal/metrics"
 	"github.com/SwissOpenEM/globus-transfer-service/internal/serviceuser"
+	"github.com/SwissOpenEM/globus-transfer-service/internal/store"
 	"github.com/SwissOpenEM/globus-transfer-service/internal/tasks"
+	"golang.org/x/oauth2"
+)
+
+const (
+	defaultStateFile                = "globus-transfer-service-state.db"
+	defaultReconciliationReportFile = "globus-transfer-service-reconciliation.json"
+	defaultShutdownTimeout          = 30 * time.Second
+	defaultPriorityAgingInterval    = 600
 )
 
 func main() {
@@ -23,32 +37,111 @@ func main() {
 		log.Fatalf("couldn't read config: %s\n", err.Error())
 	}
 
-	serviceUser, err := serviceuser.CreateServiceUser(conf.ScicatUrl, scicatServiceUserUsername, scicatServiceUserPassword)
+	m := metrics.New()
+
+	// every request to SciCat goes through the default client, while the globus client gets its own
+	http.DefaultClient.Transport = m.InstrumentTransport("scicat", http.DefaultTransport)
+	globusCtx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{
+		Transport: m.InstrumentTransport("globus", http.DefaultTransport),
+	})
+
+	serviceUser, err := serviceuser.CreateServiceUser(conf.ScicatUrl, scicatServiceUserUsername, scicatServiceUserPassword, m)
 	if err != nil {
 		log.Fatalf("couldn't create service user: %s\n", err.Error())
 	}
 
-	globusClient, err := globus.AuthCreateServiceClient(context.Background(), globusClientId, globusClientSecret, conf.GlobusScopes)
+	globusClient, err := globus.AuthCreateServiceClient(globusCtx, globusClientId, globusClientSecret, conf.GlobusScopes)
 	if err != nil {
 		log.Fatalf("couldn't create globus client: %s\n", err.Error())
 	}
 
-	taskPool := tasks.CreateTaskPool(conf.ScicatUrl, globusClient, serviceUser, conf.Task.MaxConcurrency, conf.Task.QueueSize, conf.Task.PollInterval)
+	stateFile := conf.StateFile
+	if stateFile == "" {
+		stateFile = defaultStateFile
+	}
+	transferStore, err := store.Open(stateFile)
+	if err != nil {
+		log.Fatalf("couldn't open the local state store at '%s': %s\n", stateFile, err.Error())
+	}
+	defer transferStore.Close()
+
+	priorityAgingInterval := conf.Priorities.AgingInterval
+	if priorityAgingInterval == 0 {
+		priorityAgingInterval = defaultPriorityAgingInterval
+	}
+	taskPool := tasks.CreateTaskPool(tasks.Config{
+		ScicatUrl:             conf.ScicatUrl,
+		MaxConcurrency:        conf.Task.MaxConcurrency,
+		QueueSize:             conf.Task.QueueSize,
+		SubmissionLimits:      conf.SubmissionLimits,
+		PriorityAgingInterval: priorityAgingInterval,
+		FairShare:             conf.FairShare,
+		TaskPollInterval:      conf.Task.PollInterval,
+		StalledGracePeriod:    conf.Task.StalledGracePeriod,
+		MaxConsecutiveErrors:  conf.Task.MaxConsecutiveErrors,
+	}, globusClient, serviceUser, transferStore, m)
 
-	err = tasks.RestoreGlobusTransferJobsFromScicat(conf.ScicatUrl, serviceUser, taskPool)
+	report, err := taskPool.Reconcile()
 	if err != nil {
-		log.Fatalf("couldn't resume unfinished jobs: %s\n", err.Error())
+		log.Fatalf("couldn't reconcile unfinished jobs: %s\n", err.Error())
+	}
+	log.Println(report.Summary())
+	reportFile := conf.ReconciliationReportFile
+	if reportFile == "" {
+		reportFile = defaultReconciliationReportFile
 	}
+	if err := report.WriteFile(reportFile); err != nil {
+		log.Printf("couldn't write the reconciliation report to '%s': %s\n", reportFile, err.Error())
+	}
+	taskPool.Start()
 
-	serverHandler, err := api.NewServerHandler(globusClient, conf.GlobusScopes, conf.ScicatUrl, serviceUser, conf.FacilityCollectionIDs, conf.FacilitySrcGroupTemplate, conf.FacilityDstGroupTemplate, conf.DstPathTemplate, taskPool)
+	serverHandler, err := api.NewServerHandler(globusClient, conf.GlobusScopes, conf.ScicatUrl, serviceUser, conf.FacilityCollectionIDs, conf.FacilitySrcGroupTemplate, conf.FacilityDstGroupTemplate, conf.DstPathTemplate, conf.RetrievalPathTemplate, conf.TransferOptions, conf.Priorities.GroupTemplates, conf.Quotas, taskPool)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	server, err := api.NewServer(&serverHandler, conf.Port, conf.ScicatUrl)
+	server, err := api.NewServer(&serverHandler, conf.Port, conf.ScicatUrl, m)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	log.Fatal(server.ListenAndServe())
+	// the event streams never end by themselves, so they're closed for the ongoing requests to be drained
+	server.RegisterOnShutdown(taskPool.CloseTransferEvents)
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	serverFailed := false
+	select {
+	case err := <-serverErr:
+		// the transfers are still stopped and the store closed, so that the next start resumes them
+		log.Printf("the server stopped, shutting down: %s\n", err.Error())
+		serverFailed = true
+	case sig := <-signals:
+		log.Printf("received %s, shutting down\n", sig)
+	}
+
+	shutdownTimeout := time.Duration(conf.ShutdownTimeout) * time.Second
+	if shutdownTimeout == 0 {
+		shutdownTimeout = defaultShutdownTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("couldn't wait for the ongoing requests to complete: %s\n", err.Error())
+	}
+	if err := taskPool.Shutdown(ctx); err != nil {
+		log.Printf("couldn't wait for the transfer tasks to stop: %s\n", err.Error())
+	}
+	log.Println("shut down, the unfinished transfers will be resumed at the next start")
+	if serverFailed {
+		// exiting skips the deferred calls
+		cancel()
+		transferStore.Close()
+		os.Exit(1)
+	}
 }
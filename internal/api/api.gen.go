@@ -10,10 +10,12 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/gin-gonic/gin"
@@ -25,6 +27,94 @@ const (
 	ScicatKeyAuthScopes = "ScicatKeyAuth.Scopes"
 )
 
+// Defines values for QuotaExceededQuota.
+const (
+	ActiveTransfers QuotaExceededQuota = "activeTransfers"
+	DailyBytes      QuotaExceededQuota = "dailyBytes"
+	MonthlyBytes    QuotaExceededQuota = "monthlyBytes"
+	QueuedTransfers QuotaExceededQuota = "queuedTransfers"
+)
+
+// Defines values for TransferOptionsSyncLevel.
+const (
+	Checksum TransferOptionsSyncLevel = "checksum"
+	Exists   TransferOptionsSyncLevel = "exists"
+	Mtime    TransferOptionsSyncLevel = "mtime"
+	Size     TransferOptionsSyncLevel = "size"
+)
+
+// Defines values for TransferPriority.
+const (
+	High   TransferPriority = "high"
+	Low    TransferPriority = "low"
+	Normal TransferPriority = "normal"
+	Urgent TransferPriority = "urgent"
+)
+
+// Defines values for TransferStatus.
+const (
+	Cancelled     TransferStatus = "cancelled"
+	Failed        TransferStatus = "failed"
+	Finished      TransferStatus = "finished"
+	InvalidStatus TransferStatus = "invalid status"
+	Stalled       TransferStatus = "stalled"
+	Submitting    TransferStatus = "submitting"
+	Transferring  TransferStatus = "transferring"
+	Waiting       TransferStatus = "waiting"
+)
+
+// DatasetToTransfer a dataset to transfer as part of a batch transfer request
+type DatasetToTransfer struct {
+	// FileList the files of the dataset to transfer, the whole dataset is transferred if it's not set. An empty list is rejected
+	FileList *[]FileToTransfer `json:"fileList,omitempty"`
+
+	// ScicatPid the SciCat PID of the dataset
+	ScicatPid string `json:"scicatPid"`
+}
+
+// DatasetTransferFiles the outcome of each file of the current Globus task of a dataset
+type DatasetTransferFiles struct {
+	GlobusTaskId string `json:"globusTaskId"`
+	ScicatPid    string `json:"scicatPid"`
+
+	// Skipped the files that were skipped because of errors
+	Skipped []SkippedFile `json:"skipped"`
+
+	// Transferred the files that were transferred successfully
+	Transferred []TransferredFile `json:"transferred"`
+}
+
+// DatasetTransferItem the state of the transfer of a single dataset of a transfer job
+type DatasetTransferItem struct {
+	BytesTransferred *int `json:"bytesTransferred,omitempty"`
+
+	// FailedFiles the first few of the files that were skipped because of errors
+	FailedFiles *[]SkippedFile `json:"failedFiles,omitempty"`
+
+	// FilesFailed the number of files that were skipped because of errors
+	FilesFailed      *int    `json:"filesFailed,omitempty"`
+	FilesTotal       *int    `json:"filesTotal,omitempty"`
+	FilesTransferred *int    `json:"filesTransferred,omitempty"`
+	Message          *string `json:"message,omitempty"`
+	ScicatPid        string  `json:"scicatPid"`
+
+	// StalledSince since when Globus doesn't progress with the transfer because its task is inactive or paused, only set while the transfer is stalled
+	StalledSince *time.Time     `json:"stalledSince,omitempty"`
+	Status       TransferStatus `json:"status"`
+}
+
+// Facility a facility that can take part in transfers, along with the user's rights to use it
+type Facility struct {
+	// CanBeDestination whether the user may request transfers to this facility
+	CanBeDestination bool `json:"canBeDestination"`
+
+	// CanBeSource whether the user may request transfers from this facility
+	CanBeSource bool `json:"canBeSource"`
+
+	// Name the identifier name of the facility
+	Name string `json:"name"`
+}
+
 // FileToTransfer the file to transfer as part of a transfer request
 type FileToTransfer struct {
 	// IsSymlink specifies whether this file is a symlink
@@ -34,6 +124,184 @@ type FileToTransfer struct {
 	Path string `json:"path"`
 }
 
+// GroupQuota the usage of the quotas of an owner group, the limits aren't set if they're unlimited
+type GroupQuota struct {
+	// DailyBytes the bytes of the transfers of the group requested in the last 24 hours, which is the size of the datasets until the transfers end and the bytes that were transferred afterwards
+	DailyBytes         int64  `json:"dailyBytes"`
+	Group              string `json:"group"`
+	MaxDailyBytes      *int64 `json:"maxDailyBytes,omitempty"`
+	MaxMonthlyBytes    *int64 `json:"maxMonthlyBytes,omitempty"`
+	MaxQueuedTransfers *int   `json:"maxQueuedTransfers,omitempty"`
+
+	// MonthlyBytes same as dailyBytes, for the transfers requested in the last 30 days
+	MonthlyBytes int64 `json:"monthlyBytes"`
+
+	// QueuedTransfers the transfers of the group that wait in the queue
+	QueuedTransfers int `json:"queuedTransfers"`
+}
+
+// QuotaExceeded the quota that a request would exceed
+type QuotaExceeded struct {
+	// Group the group whose quota would be exceeded, not set for the quotas of the user
+	Group   *string `json:"group,omitempty"`
+	Limit   int64   `json:"limit"`
+	Message string  `json:"message"`
+
+	// Quota the quota that would be exceeded
+	Quota QuotaExceededQuota `json:"quota"`
+
+	// Remaining what can still be requested within the quota
+	Remaining int64 `json:"remaining"`
+
+	// Requested what the request would add to the usage, the number of transfers or the size of the datasets in bytes
+	Requested int64 `json:"requested"`
+	Used      int64 `json:"used"`
+}
+
+// QuotaExceededQuota the quota that would be exceeded
+type QuotaExceededQuota string
+
+// QuotaUsage the usage of the quotas of the user and of the user's groups
+type QuotaUsage struct {
+	Groups []GroupQuota `json:"groups"`
+
+	// User the usage of the quotas of a user, the limits aren't set if they're unlimited
+	User UserQuota `json:"user"`
+}
+
+// SkippedFile a file that was skipped because of an error
+type SkippedFile struct {
+	DestinationPath *string `json:"destinationPath,omitempty"`
+
+	// ErrorCode the Globus error code
+	ErrorCode    string  `json:"errorCode"`
+	ErrorDetails *string `json:"errorDetails,omitempty"`
+	SourcePath   string  `json:"sourcePath"`
+}
+
+// TransferFiles the outcome of each file of a transfer job
+type TransferFiles struct {
+	Datasets   []DatasetTransferFiles `json:"datasets"`
+	TransferId string                 `json:"transferId"`
+}
+
+// TransferItem defines model for TransferItem.
+type TransferItem struct {
+	BytesTotal       *int                   `json:"bytesTotal,omitempty"`
+	BytesTransferred *int                   `json:"bytesTransferred,omitempty"`
+	CreatedAt        *time.Time             `json:"createdAt,omitempty"`
+	DatasetPids      *[]string              `json:"datasetPids,omitempty"`
+	Datasets         *[]DatasetTransferItem `json:"datasets,omitempty"`
+
+	// Deadline the time after which the transfer is cancelled and fails if it hasn't completed
+	Deadline            *time.Time `json:"deadline,omitempty"`
+	DestinationFacility *string    `json:"destinationFacility,omitempty"`
+
+	// FilesFailed the number of files that were skipped because of errors
+	FilesFailed      *int    `json:"filesFailed,omitempty"`
+	FilesTotal       *int    `json:"filesTotal,omitempty"`
+	FilesTransferred *int    `json:"filesTransferred,omitempty"`
+	Message          *string `json:"message,omitempty"`
+
+	// Priority the priority with which the transfer is submitted to Globus and tracked, normal if not set. Some priorities can be restricted to the members of groups. The priority of a transfer waiting in the queue is raised over time, so that transfers of low priority aren't held back forever
+	Priority *TransferPriority `json:"priority,omitempty"`
+
+	// QueuePosition the position of the transfer in the queue of the transfers waiting to be submitted to Globus, starting at 1. Only set while the transfer waits in the queue
+	QueuePosition *int `json:"queuePosition,omitempty"`
+
+	// Retrieval whether the transfer retrieves a dataset from a facility it was previously transferred to
+	Retrieval      *bool          `json:"retrieval,omitempty"`
+	SourceFacility *string        `json:"sourceFacility,omitempty"`
+	Status         TransferStatus `json:"status"`
+	TransferId     string         `json:"transferId"`
+}
+
+// TransferOptions the options of the Globus transfer. The options that aren't set take the defaults configured for the pair of facilities, and the configuration can restrict the values that are allowed
+type TransferOptions struct {
+	// Deadline the time by which the transfer has to complete, after which it is cancelled and fails. It can't be later than the maximum duration configured for the pair of facilities from the request. Without a deadline, the transfer has the maximum duration from when it leaves the queue
+	Deadline *time.Time `json:"deadline,omitempty"`
+
+	// DeleteDestinationExtra delete the files at the destination that don't exist at the source
+	DeleteDestinationExtra *bool `json:"deleteDestinationExtra,omitempty"`
+
+	// EncryptData encrypt the data channel of the transfer
+	EncryptData *bool `json:"encryptData,omitempty"`
+
+	// FailOnQuotaErrors fail the transfer when the destination quota is exceeded instead of retrying
+	FailOnQuotaErrors *bool `json:"failOnQuotaErrors,omitempty"`
+
+	// Label the label of the Globus task
+	Label *string `json:"label,omitempty"`
+
+	// PreserveTimestamp preserve the modification time of the files at the destination
+	PreserveTimestamp *bool `json:"preserveTimestamp,omitempty"`
+
+	// SkipSourceErrors skip the files that can't be read at the source instead of failing the transfer
+	SkipSourceErrors *bool `json:"skipSourceErrors,omitempty"`
+
+	// SyncLevel only transfer the files that don't exist at the destination, or whose size, modification time or checksum differs
+	SyncLevel *TransferOptionsSyncLevel `json:"syncLevel,omitempty"`
+
+	// VerifyChecksum verify the checksum of the transferred files
+	VerifyChecksum *bool `json:"verifyChecksum,omitempty"`
+}
+
+// TransferOptionsSyncLevel only transfer the files that don't exist at the destination, or whose size, modification time or checksum differs
+type TransferOptionsSyncLevel string
+
+// TransferPriority the priority with which the transfer is submitted to Globus and tracked, normal if not set. Some priorities can be restricted to the members of groups. The priority of a transfer waiting in the queue is raised over time, so that transfers of low priority aren't held back forever
+type TransferPriority string
+
+// TransferStatus defines model for TransferStatus.
+type TransferStatus string
+
+// TransferValidation the result of validating a transfer request
+type TransferValidation struct {
+	// DestCollectionId the Globus collection id of the destination facility
+	DestCollectionId *string `json:"destCollectionId,omitempty"`
+
+	// DestPath the path the dataset would be transferred to in the destination collection
+	DestPath     *string           `json:"destPath,omitempty"`
+	FailedChecks []ValidationCheck `json:"failedChecks"`
+
+	// JobId the job created by an earlier request with the same idempotency key, which requesting the transfer would return instead of starting another one
+	JobId *string `json:"jobId,omitempty"`
+
+	// SourceCollectionId the Globus collection id of the source facility
+	SourceCollectionId *string `json:"sourceCollectionId,omitempty"`
+
+	// SourcePath the path of the dataset in the source collection
+	SourcePath *string `json:"sourcePath,omitempty"`
+
+	// Valid whether all checks passed, so that requesting this transfer is expected to succeed
+	Valid bool `json:"valid"`
+}
+
+// TransferredFile a file that was transferred successfully
+type TransferredFile struct {
+	DestinationPath string `json:"destinationPath"`
+	SourcePath      string `json:"sourcePath"`
+}
+
+// UserQuota the usage of the quotas of a user, the limits aren't set if they're unlimited
+type UserQuota struct {
+	// ActiveTransfers the transfers of the user that are queued or ongoing
+	ActiveTransfers    int  `json:"activeTransfers"`
+	MaxActiveTransfers *int `json:"maxActiveTransfers,omitempty"`
+}
+
+// ValidationCheck a check that failed during the validation of a transfer request
+type ValidationCheck struct {
+	// Check the name of the check, one of 'sourceFacility', 'destFacility', 'dataset', 'groups', 'idempotencyKey', 'duplicate', 'queue', 'quota', 'destPath', 'options', 'priority' or 'fileList'
+	Check string `json:"check"`
+
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the reason of the failure
+	Message string `json:"message"`
+}
+
 // GeneralErrorResponse defines model for GeneralErrorResponse.
 type GeneralErrorResponse struct {
 	// Details further details, debugging information
@@ -43,9 +311,43 @@ type GeneralErrorResponse struct {
 	Message *string `json:"message,omitempty"`
 }
 
+// GetTransferTasksParams defines parameters for GetTransferTasks.
+type GetTransferTasksParams struct {
+	// Status only list transfers with this status, one of 'submitting', 'waiting', 'transferring', 'stalled', 'finished', 'failed' or 'cancelled'
+	Status *string `form:"status,omitempty" json:"status,omitempty"`
+
+	// ScicatPid only list transfers of the dataset with this pid
+	ScicatPid *string `form:"scicatPid,omitempty" json:"scicatPid,omitempty"`
+
+	// SourceFacility only list transfers from this facility
+	SourceFacility *string `form:"sourceFacility,omitempty" json:"sourceFacility,omitempty"`
+
+	// DestFacility only list transfers to this facility
+	DestFacility *string `form:"destFacility,omitempty" json:"destFacility,omitempty"`
+
+	// CreatedAfter only list transfers requested after this point in time
+	CreatedAfter *time.Time `form:"createdAfter,omitempty" json:"createdAfter,omitempty"`
+
+	// CreatedBefore only list transfers requested before this point in time
+	CreatedBefore *time.Time `form:"createdBefore,omitempty" json:"createdBefore,omitempty"`
+
+	// Limit the maximum number of transfers to return, 20 by default
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Skip the number of transfers to skip, for pagination
+	Skip *int `form:"skip,omitempty" json:"skip,omitempty"`
+}
+
 // PostTransferTaskJSONBody defines parameters for PostTransferTask.
 type PostTransferTaskJSONBody struct {
+	// FileList the files of the dataset to transfer, the whole dataset is transferred if it's not set. An empty list is rejected
 	FileList *[]FileToTransfer `json:"fileList,omitempty"`
+
+	// Options the options of the Globus transfer. The options that aren't set take the defaults configured for the pair of facilities, and the configuration can restrict the values that are allowed
+	Options *TransferOptions `json:"options,omitempty"`
+
+	// Priority the priority with which the transfer is submitted to Globus and tracked, normal if not set. Some priorities can be restricted to the members of groups. The priority of a transfer waiting in the queue is raised over time, so that transfers of low priority aren't held back forever
+	Priority *TransferPriority `json:"priority,omitempty"`
 }
 
 // PostTransferTaskParams defines parameters for PostTransferTask.
@@ -58,6 +360,71 @@ type PostTransferTaskParams struct {
 
 	// ScicatPid the pid of the dataset being transferred
 	ScicatPid string `form:"scicatPid" json:"scicatPid"`
+
+	// IdempotencyKey a key identifying the request, repeating a request with the same key returns the job created by the first one instead of starting another transfer
+	IdempotencyKey *string `json:"Idempotency-Key,omitempty"`
+}
+
+// PostBatchTransferTaskJSONBody defines parameters for PostBatchTransferTask.
+type PostBatchTransferTaskJSONBody struct {
+	Datasets []DatasetToTransfer `json:"datasets"`
+
+	// Options the options of the Globus transfer. The options that aren't set take the defaults configured for the pair of facilities, and the configuration can restrict the values that are allowed
+	Options *TransferOptions `json:"options,omitempty"`
+
+	// Priority the priority with which the transfer is submitted to Globus and tracked, normal if not set. Some priorities can be restricted to the members of groups. The priority of a transfer waiting in the queue is raised over time, so that transfers of low priority aren't held back forever
+	Priority *TransferPriority `json:"priority,omitempty"`
+}
+
+// PostBatchTransferTaskParams defines parameters for PostBatchTransferTask.
+type PostBatchTransferTaskParams struct {
+	// SourceFacility the identifier name of the source facility
+	SourceFacility string `form:"sourceFacility" json:"sourceFacility"`
+
+	// DestFacility the identifier name of the destination facility
+	DestFacility string `form:"destFacility" json:"destFacility"`
+
+	// IdempotencyKey a key identifying the request, repeating a request with the same key returns the job created by the first one instead of starting other transfers
+	IdempotencyKey *string `json:"Idempotency-Key,omitempty"`
+}
+
+// PostRetrievalTaskParams defines parameters for PostRetrievalTask.
+type PostRetrievalTaskParams struct {
+	// SourceFacility the identifier name of the facility the dataset was transferred to, which it's retrieved from
+	SourceFacility string `form:"sourceFacility" json:"sourceFacility"`
+
+	// DestFacility the identifier name of the facility to retrieve the dataset to
+	DestFacility string `form:"destFacility" json:"destFacility"`
+
+	// ScicatPid the pid of the dataset being retrieved
+	ScicatPid string `form:"scicatPid" json:"scicatPid"`
+}
+
+// ValidateTransferTaskJSONBody defines parameters for ValidateTransferTask.
+type ValidateTransferTaskJSONBody struct {
+	// FileList the files of the dataset to transfer, the whole dataset is transferred if it's not set. An empty list is rejected
+	FileList *[]FileToTransfer `json:"fileList,omitempty"`
+
+	// Options the options of the Globus transfer. The options that aren't set take the defaults configured for the pair of facilities, and the configuration can restrict the values that are allowed
+	Options *TransferOptions `json:"options,omitempty"`
+
+	// Priority the priority with which the transfer is submitted to Globus and tracked, normal if not set. Some priorities can be restricted to the members of groups. The priority of a transfer waiting in the queue is raised over time, so that transfers of low priority aren't held back forever
+	Priority *TransferPriority `json:"priority,omitempty"`
+}
+
+// ValidateTransferTaskParams defines parameters for ValidateTransferTask.
+type ValidateTransferTaskParams struct {
+	// SourceFacility the identifier name of the source facility
+	SourceFacility string `form:"sourceFacility" json:"sourceFacility"`
+
+	// DestFacility the identifier name of the destination facility
+	DestFacility string `form:"destFacility" json:"destFacility"`
+
+	// ScicatPid the pid of the dataset to be transferred
+	ScicatPid string `form:"scicatPid" json:"scicatPid"`
+
+	// IdempotencyKey the idempotency key of the transfer request, a key that was already used returns the job created by the first request
+	IdempotencyKey *string `json:"Idempotency-Key,omitempty"`
 }
 
 // DeleteTransferTaskParams defines parameters for DeleteTransferTask.
@@ -69,14 +436,53 @@ type DeleteTransferTaskParams struct {
 // PostTransferTaskJSONRequestBody defines body for PostTransferTask for application/json ContentType.
 type PostTransferTaskJSONRequestBody PostTransferTaskJSONBody
 
+// PostBatchTransferTaskJSONRequestBody defines body for PostBatchTransferTask for application/json ContentType.
+type PostBatchTransferTaskJSONRequestBody PostBatchTransferTaskJSONBody
+
+// ValidateTransferTaskJSONRequestBody defines body for ValidateTransferTask for application/json ContentType.
+type ValidateTransferTaskJSONRequestBody ValidateTransferTaskJSONBody
+
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
+	// list facilities
+	// (GET /facilities)
+	GetFacilities(c *gin.Context)
+	// get the usage of the quotas
+	// (GET /quota)
+	GetQuota(c *gin.Context)
+	// list transfers
+	// (GET /transfer)
+	GetTransferTasks(c *gin.Context, params GetTransferTasksParams)
 	// request a transfer task
 	// (POST /transfer)
 	PostTransferTask(c *gin.Context, params PostTransferTaskParams)
+	// request a transfer task for several datasets
+	// (POST /transfer/batch)
+	PostBatchTransferTask(c *gin.Context, params PostBatchTransferTaskParams)
+	// stream the status changes of transfers
+	// (GET /transfer/events)
+	GetTransferEvents(c *gin.Context)
+	// request the retrieval of a dataset
+	// (POST /transfer/retrieval)
+	PostRetrievalTask(c *gin.Context, params PostRetrievalTaskParams)
+	// validate a transfer request without starting it
+	// (POST /transfer/validate)
+	ValidateTransferTask(c *gin.Context, params ValidateTransferTaskParams)
 	// cancels and/or deletes transfer entry
 	// (DELETE /transfer/{scicatJobId})
 	DeleteTransferTask(c *gin.Context, scicatJobId string, params DeleteTransferTaskParams)
+	// get the status of a transfer
+	// (GET /transfer/{scicatJobId})
+	GetTransferTask(c *gin.Context, scicatJobId string)
+	// stream the status changes of a transfer
+	// (GET /transfer/{scicatJobId}/events)
+	GetTransferTaskEvents(c *gin.Context, scicatJobId string)
+	// get the outcome of each file of a transfer
+	// (GET /transfer/{scicatJobId}/files)
+	GetTransferTaskFiles(c *gin.Context, scicatJobId string)
+	// retry a failed or cancelled transfer
+	// (POST /transfer/{scicatJobId}/retry)
+	RetryTransferTask(c *gin.Context, scicatJobId string)
 }
 
 // ServerInterfaceWrapper converts contexts to parameters.
@@ -88,6 +494,120 @@ type ServerInterfaceWrapper struct {
 
 type MiddlewareFunc func(c *gin.Context)
 
+// GetFacilities operation middleware
+func (siw *ServerInterfaceWrapper) GetFacilities(c *gin.Context) {
+
+	c.Set(ScicatKeyAuthScopes, []string{})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetFacilities(c)
+}
+
+// GetQuota operation middleware
+func (siw *ServerInterfaceWrapper) GetQuota(c *gin.Context) {
+
+	c.Set(ScicatKeyAuthScopes, []string{})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetQuota(c)
+}
+
+// GetTransferTasks operation middleware
+func (siw *ServerInterfaceWrapper) GetTransferTasks(c *gin.Context) {
+
+	var err error
+
+	c.Set(ScicatKeyAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetTransferTasksParams
+
+	// ------------- Optional query parameter "status" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "status", c.Request.URL.Query(), &params.Status)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter status: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "scicatPid" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "scicatPid", c.Request.URL.Query(), &params.ScicatPid)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter scicatPid: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "sourceFacility" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sourceFacility", c.Request.URL.Query(), &params.SourceFacility)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sourceFacility: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "destFacility" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "destFacility", c.Request.URL.Query(), &params.DestFacility)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter destFacility: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "createdAfter" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "createdAfter", c.Request.URL.Query(), &params.CreatedAfter)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter createdAfter: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "createdBefore" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "createdBefore", c.Request.URL.Query(), &params.CreatedBefore)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter createdBefore: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", c.Request.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter limit: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Optional query parameter "skip" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "skip", c.Request.URL.Query(), &params.Skip)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter skip: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetTransferTasks(c, params)
+}
+
 // PostTransferTask operation middleware
 func (siw *ServerInterfaceWrapper) PostTransferTask(c *gin.Context) {
 
@@ -143,6 +663,27 @@ func (siw *ServerInterfaceWrapper) PostTransferTask(c *gin.Context) {
 		return
 	}
 
+	headers := c.Request.Header
+
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandler(c, fmt.Errorf("Expected one value for Idempotency-Key, got %d", n), http.StatusBadRequest)
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter Idempotency-Key: %w", err), http.StatusBadRequest)
+			return
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+
+	}
+
 	for _, middleware := range siw.HandlerMiddlewares {
 		middleware(c)
 		if c.IsAborted() {
@@ -153,75 +694,1052 @@ func (siw *ServerInterfaceWrapper) PostTransferTask(c *gin.Context) {
 	siw.Handler.PostTransferTask(c, params)
 }
 
-// DeleteTransferTask operation middleware
-func (siw *ServerInterfaceWrapper) DeleteTransferTask(c *gin.Context) {
+// PostBatchTransferTask operation middleware
+func (siw *ServerInterfaceWrapper) PostBatchTransferTask(c *gin.Context) {
 
 	var err error
 
-	// ------------- Path parameter "scicatJobId" -------------
-	var scicatJobId string
+	c.Set(ScicatKeyAuthScopes, []string{})
 
-	err = runtime.BindStyledParameterWithOptions("simple", "scicatJobId", c.Param("scicatJobId"), &scicatJobId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PostBatchTransferTaskParams
+
+	// ------------- Required query parameter "sourceFacility" -------------
+
+	if paramValue := c.Query("sourceFacility"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument sourceFacility is required, but not found"), http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "sourceFacility", c.Request.URL.Query(), &params.SourceFacility)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter scicatJobId: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sourceFacility: %w", err), http.StatusBadRequest)
 		return
 	}
 
-	c.Set(ScicatKeyAuthScopes, []string{})
+	// ------------- Required query parameter "destFacility" -------------
 
-	// Parameter object where we will unmarshal all parameters from the context
-	var params DeleteTransferTaskParams
+	if paramValue := c.Query("destFacility"); paramValue != "" {
 
-	// ------------- Optional query parameter "delete" -------------
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument destFacility is required, but not found"), http.StatusBadRequest)
+		return
+	}
 
-	err = runtime.BindQueryParameter("form", true, false, "delete", c.Request.URL.Query(), &params.Delete)
+	err = runtime.BindQueryParameter("form", true, true, "destFacility", c.Request.URL.Query(), &params.DestFacility)
 	if err != nil {
-		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter delete: %w", err), http.StatusBadRequest)
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter destFacility: %w", err), http.StatusBadRequest)
 		return
 	}
 
-	for _, middleware := range siw.HandlerMiddlewares {
-		middleware(c)
-		if c.IsAborted() {
+	headers := c.Request.Header
+
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandler(c, fmt.Errorf("Expected one value for Idempotency-Key, got %d", n), http.StatusBadRequest)
 			return
 		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter Idempotency-Key: %w", err), http.StatusBadRequest)
+			return
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostBatchTransferTask(c, params)
+}
+
+// GetTransferEvents operation middleware
+func (siw *ServerInterfaceWrapper) GetTransferEvents(c *gin.Context) {
+
+	c.Set(ScicatKeyAuthScopes, []string{})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetTransferEvents(c)
+}
+
+// PostRetrievalTask operation middleware
+func (siw *ServerInterfaceWrapper) PostRetrievalTask(c *gin.Context) {
+
+	var err error
+
+	c.Set(ScicatKeyAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PostRetrievalTaskParams
+
+	// ------------- Required query parameter "sourceFacility" -------------
+
+	if paramValue := c.Query("sourceFacility"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument sourceFacility is required, but not found"), http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "sourceFacility", c.Request.URL.Query(), &params.SourceFacility)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sourceFacility: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Required query parameter "destFacility" -------------
+
+	if paramValue := c.Query("destFacility"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument destFacility is required, but not found"), http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "destFacility", c.Request.URL.Query(), &params.DestFacility)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter destFacility: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Required query parameter "scicatPid" -------------
+
+	if paramValue := c.Query("scicatPid"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument scicatPid is required, but not found"), http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "scicatPid", c.Request.URL.Query(), &params.ScicatPid)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter scicatPid: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.PostRetrievalTask(c, params)
+}
+
+// ValidateTransferTask operation middleware
+func (siw *ServerInterfaceWrapper) ValidateTransferTask(c *gin.Context) {
+
+	var err error
+
+	c.Set(ScicatKeyAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ValidateTransferTaskParams
+
+	// ------------- Required query parameter "sourceFacility" -------------
+
+	if paramValue := c.Query("sourceFacility"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument sourceFacility is required, but not found"), http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "sourceFacility", c.Request.URL.Query(), &params.SourceFacility)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter sourceFacility: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Required query parameter "destFacility" -------------
+
+	if paramValue := c.Query("destFacility"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument destFacility is required, but not found"), http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "destFacility", c.Request.URL.Query(), &params.DestFacility)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter destFacility: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	// ------------- Required query parameter "scicatPid" -------------
+
+	if paramValue := c.Query("scicatPid"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandler(c, fmt.Errorf("Query argument scicatPid is required, but not found"), http.StatusBadRequest)
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "scicatPid", c.Request.URL.Query(), &params.ScicatPid)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter scicatPid: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	headers := c.Request.Header
+
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey string
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandler(c, fmt.Errorf("Expected one value for Idempotency-Key, got %d", n), http.StatusBadRequest)
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter Idempotency-Key: %w", err), http.StatusBadRequest)
+			return
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.ValidateTransferTask(c, params)
+}
+
+// DeleteTransferTask operation middleware
+func (siw *ServerInterfaceWrapper) DeleteTransferTask(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "scicatJobId" -------------
+	var scicatJobId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "scicatJobId", c.Param("scicatJobId"), &scicatJobId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter scicatJobId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(ScicatKeyAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params DeleteTransferTaskParams
+
+	// ------------- Optional query parameter "delete" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "delete", c.Request.URL.Query(), &params.Delete)
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter delete: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.DeleteTransferTask(c, scicatJobId, params)
+}
+
+// GetTransferTask operation middleware
+func (siw *ServerInterfaceWrapper) GetTransferTask(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "scicatJobId" -------------
+	var scicatJobId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "scicatJobId", c.Param("scicatJobId"), &scicatJobId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter scicatJobId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(ScicatKeyAuthScopes, []string{})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetTransferTask(c, scicatJobId)
+}
+
+// GetTransferTaskEvents operation middleware
+func (siw *ServerInterfaceWrapper) GetTransferTaskEvents(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "scicatJobId" -------------
+	var scicatJobId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "scicatJobId", c.Param("scicatJobId"), &scicatJobId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter scicatJobId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(ScicatKeyAuthScopes, []string{})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetTransferTaskEvents(c, scicatJobId)
+}
+
+// GetTransferTaskFiles operation middleware
+func (siw *ServerInterfaceWrapper) GetTransferTaskFiles(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "scicatJobId" -------------
+	var scicatJobId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "scicatJobId", c.Param("scicatJobId"), &scicatJobId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter scicatJobId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(ScicatKeyAuthScopes, []string{})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.GetTransferTaskFiles(c, scicatJobId)
+}
+
+// RetryTransferTask operation middleware
+func (siw *ServerInterfaceWrapper) RetryTransferTask(c *gin.Context) {
+
+	var err error
+
+	// ------------- Path parameter "scicatJobId" -------------
+	var scicatJobId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "scicatJobId", c.Param("scicatJobId"), &scicatJobId, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		siw.ErrorHandler(c, fmt.Errorf("Invalid format for parameter scicatJobId: %w", err), http.StatusBadRequest)
+		return
+	}
+
+	c.Set(ScicatKeyAuthScopes, []string{})
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		middleware(c)
+		if c.IsAborted() {
+			return
+		}
+	}
+
+	siw.Handler.RetryTransferTask(c, scicatJobId)
+}
+
+// GinServerOptions provides options for the Gin server.
+type GinServerOptions struct {
+	BaseURL      string
+	Middlewares  []MiddlewareFunc
+	ErrorHandler func(*gin.Context, error, int)
+}
+
+// RegisterHandlers creates http.Handler with routing matching OpenAPI spec.
+func RegisterHandlers(router gin.IRouter, si ServerInterface) {
+	RegisterHandlersWithOptions(router, si, GinServerOptions{})
+}
+
+// RegisterHandlersWithOptions creates http.Handler with additional options
+func RegisterHandlersWithOptions(router gin.IRouter, si ServerInterface, options GinServerOptions) {
+	errorHandler := options.ErrorHandler
+	if errorHandler == nil {
+		errorHandler = func(c *gin.Context, err error, statusCode int) {
+			c.JSON(statusCode, gin.H{"msg": err.Error()})
+		}
+	}
+
+	wrapper := ServerInterfaceWrapper{
+		Handler:            si,
+		HandlerMiddlewares: options.Middlewares,
+		ErrorHandler:       errorHandler,
+	}
+
+	router.GET(options.BaseURL+"/facilities", wrapper.GetFacilities)
+	router.GET(options.BaseURL+"/quota", wrapper.GetQuota)
+	router.GET(options.BaseURL+"/transfer", wrapper.GetTransferTasks)
+	router.POST(options.BaseURL+"/transfer", wrapper.PostTransferTask)
+	router.POST(options.BaseURL+"/transfer/batch", wrapper.PostBatchTransferTask)
+	router.GET(options.BaseURL+"/transfer/events", wrapper.GetTransferEvents)
+	router.POST(options.BaseURL+"/transfer/retrieval", wrapper.PostRetrievalTask)
+	router.POST(options.BaseURL+"/transfer/validate", wrapper.ValidateTransferTask)
+	router.DELETE(options.BaseURL+"/transfer/:scicatJobId", wrapper.DeleteTransferTask)
+	router.GET(options.BaseURL+"/transfer/:scicatJobId", wrapper.GetTransferTask)
+	router.GET(options.BaseURL+"/transfer/:scicatJobId/events", wrapper.GetTransferTaskEvents)
+	router.GET(options.BaseURL+"/transfer/:scicatJobId/files", wrapper.GetTransferTaskFiles)
+	router.POST(options.BaseURL+"/transfer/:scicatJobId/retry", wrapper.RetryTransferTask)
+}
+
+type GeneralErrorResponseJSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+type GetFacilitiesRequestObject struct {
+}
+
+type GetFacilitiesResponseObject interface {
+	VisitGetFacilitiesResponse(w http.ResponseWriter) error
+}
+
+type GetFacilities200JSONResponse []Facility
+
+func (response GetFacilities200JSONResponse) VisitGetFacilitiesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetFacilities401JSONResponse struct {
+	GeneralErrorResponseJSONResponse
+}
+
+func (response GetFacilities401JSONResponse) VisitGetFacilitiesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(401)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetFacilities500JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response GetFacilities500JSONResponse) VisitGetFacilitiesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetQuotaRequestObject struct {
+}
+
+type GetQuotaResponseObject interface {
+	VisitGetQuotaResponse(w http.ResponseWriter) error
+}
+
+type GetQuota200JSONResponse QuotaUsage
+
+func (response GetQuota200JSONResponse) VisitGetQuotaResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetQuota401JSONResponse struct {
+	GeneralErrorResponseJSONResponse
+}
+
+func (response GetQuota401JSONResponse) VisitGetQuotaResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(401)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetQuota500JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response GetQuota500JSONResponse) VisitGetQuotaResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetTransferTasksRequestObject struct {
+	Params GetTransferTasksParams
+}
+
+type GetTransferTasksResponseObject interface {
+	VisitGetTransferTasksResponse(w http.ResponseWriter) error
+}
+
+type GetTransferTasks200JSONResponse struct {
+	// Total the total number of transfers matching the filters
+	Total     int            `json:"total"`
+	Transfers []TransferItem `json:"transfers"`
+}
+
+func (response GetTransferTasks200JSONResponse) VisitGetTransferTasksResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetTransferTasks400JSONResponse struct {
+	GeneralErrorResponseJSONResponse
+}
+
+func (response GetTransferTasks400JSONResponse) VisitGetTransferTasksResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetTransferTasks401JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response GetTransferTasks401JSONResponse) VisitGetTransferTasksResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(401)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetTransferTasks500JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response GetTransferTasks500JSONResponse) VisitGetTransferTasksResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostTransferTaskRequestObject struct {
+	Params PostTransferTaskParams
+	Body   *PostTransferTaskJSONRequestBody
+}
+
+type PostTransferTaskResponseObject interface {
+	VisitPostTransferTaskResponse(w http.ResponseWriter) error
+}
+
+type PostTransferTask200JSONResponse struct {
+	// JobId the SciCat job id of the transfer job
+	JobId string `json:"jobId"`
+}
+
+func (response PostTransferTask200JSONResponse) VisitPostTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostTransferTask400JSONResponse struct {
+	GeneralErrorResponseJSONResponse
+}
+
+func (response PostTransferTask400JSONResponse) VisitPostTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostTransferTask401JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response PostTransferTask401JSONResponse) VisitPostTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(401)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostTransferTask403JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response PostTransferTask403JSONResponse) VisitPostTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(403)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostTransferTask409JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response PostTransferTask409JSONResponse) VisitPostTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(409)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostTransferTask429JSONResponse QuotaExceeded
+
+func (response PostTransferTask429JSONResponse) VisitPostTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(429)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostTransferTask500JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response PostTransferTask500JSONResponse) VisitPostTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostTransferTask503JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response PostTransferTask503JSONResponse) VisitPostTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostBatchTransferTaskRequestObject struct {
+	Params PostBatchTransferTaskParams
+	Body   *PostBatchTransferTaskJSONRequestBody
+}
+
+type PostBatchTransferTaskResponseObject interface {
+	VisitPostBatchTransferTaskResponse(w http.ResponseWriter) error
+}
+
+type PostBatchTransferTask200JSONResponse struct {
+	// JobId the SciCat job id of the transfer job
+	JobId string `json:"jobId"`
+}
+
+func (response PostBatchTransferTask200JSONResponse) VisitPostBatchTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostBatchTransferTask400JSONResponse struct {
+	GeneralErrorResponseJSONResponse
+}
+
+func (response PostBatchTransferTask400JSONResponse) VisitPostBatchTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostBatchTransferTask401JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response PostBatchTransferTask401JSONResponse) VisitPostBatchTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(401)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostBatchTransferTask403JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response PostBatchTransferTask403JSONResponse) VisitPostBatchTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(403)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostBatchTransferTask409JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response PostBatchTransferTask409JSONResponse) VisitPostBatchTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(409)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostBatchTransferTask429JSONResponse QuotaExceeded
+
+func (response PostBatchTransferTask429JSONResponse) VisitPostBatchTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(429)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostBatchTransferTask500JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response PostBatchTransferTask500JSONResponse) VisitPostBatchTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostBatchTransferTask503JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response PostBatchTransferTask503JSONResponse) VisitPostBatchTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetTransferEventsRequestObject struct {
+}
+
+type GetTransferEventsResponseObject interface {
+	VisitGetTransferEventsResponse(w http.ResponseWriter) error
+}
+
+type GetTransferEvents200TexteventStreamResponse struct {
+	Body          io.Reader
+	ContentLength int64
+}
+
+func (response GetTransferEvents200TexteventStreamResponse) VisitGetTransferEventsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "text/event-stream")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.WriteHeader(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
 	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
+type GetTransferEvents401JSONResponse struct {
+	GeneralErrorResponseJSONResponse
+}
+
+func (response GetTransferEvents401JSONResponse) VisitGetTransferEventsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(401)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetTransferEvents500JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response GetTransferEvents500JSONResponse) VisitGetTransferEventsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostRetrievalTaskRequestObject struct {
+	Params PostRetrievalTaskParams
+}
+
+type PostRetrievalTaskResponseObject interface {
+	VisitPostRetrievalTaskResponse(w http.ResponseWriter) error
+}
+
+type PostRetrievalTask200JSONResponse struct {
+	// JobId the SciCat job id of the retrieval job
+	JobId string `json:"jobId"`
+}
+
+func (response PostRetrievalTask200JSONResponse) VisitPostRetrievalTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostRetrievalTask400JSONResponse struct {
+	GeneralErrorResponseJSONResponse
+}
+
+func (response PostRetrievalTask400JSONResponse) VisitPostRetrievalTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostRetrievalTask401JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response PostRetrievalTask401JSONResponse) VisitPostRetrievalTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(401)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostRetrievalTask403JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response PostRetrievalTask403JSONResponse) VisitPostRetrievalTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(403)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostRetrievalTask409JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response PostRetrievalTask409JSONResponse) VisitPostRetrievalTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(409)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostRetrievalTask429JSONResponse QuotaExceeded
+
+func (response PostRetrievalTask429JSONResponse) VisitPostRetrievalTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(429)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostRetrievalTask500JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response PostRetrievalTask500JSONResponse) VisitPostRetrievalTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type PostRetrievalTask503JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response PostRetrievalTask503JSONResponse) VisitPostRetrievalTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(503)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type ValidateTransferTaskRequestObject struct {
+	Params ValidateTransferTaskParams
+	Body   *ValidateTransferTaskJSONRequestBody
+}
+
+type ValidateTransferTaskResponseObject interface {
+	VisitValidateTransferTaskResponse(w http.ResponseWriter) error
+}
 
-	siw.Handler.DeleteTransferTask(c, scicatJobId, params)
+type ValidateTransferTask200JSONResponse TransferValidation
+
+func (response ValidateTransferTask200JSONResponse) VisitValidateTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
 }
 
-// GinServerOptions provides options for the Gin server.
-type GinServerOptions struct {
-	BaseURL      string
-	Middlewares  []MiddlewareFunc
-	ErrorHandler func(*gin.Context, error, int)
+type ValidateTransferTask401JSONResponse struct {
+	GeneralErrorResponseJSONResponse
 }
 
-// RegisterHandlers creates http.Handler with routing matching OpenAPI spec.
-func RegisterHandlers(router gin.IRouter, si ServerInterface) {
-	RegisterHandlersWithOptions(router, si, GinServerOptions{})
+func (response ValidateTransferTask401JSONResponse) VisitValidateTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(401)
+
+	return json.NewEncoder(w).Encode(response)
 }
 
-// RegisterHandlersWithOptions creates http.Handler with additional options
-func RegisterHandlersWithOptions(router gin.IRouter, si ServerInterface, options GinServerOptions) {
-	errorHandler := options.ErrorHandler
-	if errorHandler == nil {
-		errorHandler = func(c *gin.Context, err error, statusCode int) {
-			c.JSON(statusCode, gin.H{"msg": err.Error()})
-		}
-	}
+type ValidateTransferTask500JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
 
-	wrapper := ServerInterfaceWrapper{
-		Handler:            si,
-		HandlerMiddlewares: options.Middlewares,
-		ErrorHandler:       errorHandler,
-	}
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
 
-	router.POST(options.BaseURL+"/transfer", wrapper.PostTransferTask)
-	router.DELETE(options.BaseURL+"/transfer/:scicatJobId", wrapper.DeleteTransferTask)
+func (response ValidateTransferTask500JSONResponse) VisitValidateTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
 }
 
-type GeneralErrorResponseJSONResponse struct {
+type DeleteTransferTaskRequestObject struct {
+	ScicatJobId string `json:"scicatJobId"`
+	Params      DeleteTransferTaskParams
+}
+
+type DeleteTransferTaskResponseObject interface {
+	VisitDeleteTransferTaskResponse(w http.ResponseWriter) error
+}
+
+type DeleteTransferTask200Response struct {
+}
+
+func (response DeleteTransferTask200Response) VisitDeleteTransferTaskResponse(w http.ResponseWriter) error {
+	w.WriteHeader(200)
+	return nil
+}
+
+type DeleteTransferTask400JSONResponse struct {
+	GeneralErrorResponseJSONResponse
+}
+
+func (response DeleteTransferTask400JSONResponse) VisitDeleteTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type DeleteTransferTask401JSONResponse struct {
 	// Details further details, debugging information
 	Details *string `json:"details,omitempty"`
 
@@ -229,39 +1747,72 @@ type GeneralErrorResponseJSONResponse struct {
 	Message *string `json:"message,omitempty"`
 }
 
-type PostTransferTaskRequestObject struct {
-	Params PostTransferTaskParams
-	Body   *PostTransferTaskJSONRequestBody
+func (response DeleteTransferTask401JSONResponse) VisitDeleteTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(401)
+
+	return json.NewEncoder(w).Encode(response)
 }
 
-type PostTransferTaskResponseObject interface {
-	VisitPostTransferTaskResponse(w http.ResponseWriter) error
+type DeleteTransferTask403JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
 }
 
-type PostTransferTask200JSONResponse struct {
-	// JobId the SciCat job id of the transfer job
-	JobId string `json:"jobId"`
+func (response DeleteTransferTask403JSONResponse) VisitDeleteTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(403)
+
+	return json.NewEncoder(w).Encode(response)
 }
 
-func (response PostTransferTask200JSONResponse) VisitPostTransferTaskResponse(w http.ResponseWriter) error {
+type DeleteTransferTask500JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response DeleteTransferTask500JSONResponse) VisitDeleteTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetTransferTaskRequestObject struct {
+	ScicatJobId string `json:"scicatJobId"`
+}
+
+type GetTransferTaskResponseObject interface {
+	VisitGetTransferTaskResponse(w http.ResponseWriter) error
+}
+
+type GetTransferTask200JSONResponse TransferItem
+
+func (response GetTransferTask200JSONResponse) VisitGetTransferTaskResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(200)
 
 	return json.NewEncoder(w).Encode(response)
 }
 
-type PostTransferTask400JSONResponse struct {
+type GetTransferTask400JSONResponse struct {
 	GeneralErrorResponseJSONResponse
 }
 
-func (response PostTransferTask400JSONResponse) VisitPostTransferTaskResponse(w http.ResponseWriter) error {
+func (response GetTransferTask400JSONResponse) VisitGetTransferTaskResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(400)
 
 	return json.NewEncoder(w).Encode(response)
 }
 
-type PostTransferTask401JSONResponse struct {
+type GetTransferTask401JSONResponse struct {
 	// Details further details, debugging information
 	Details *string `json:"details,omitempty"`
 
@@ -269,14 +1820,14 @@ type PostTransferTask401JSONResponse struct {
 	Message *string `json:"message,omitempty"`
 }
 
-func (response PostTransferTask401JSONResponse) VisitPostTransferTaskResponse(w http.ResponseWriter) error {
+func (response GetTransferTask401JSONResponse) VisitGetTransferTaskResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(401)
 
 	return json.NewEncoder(w).Encode(response)
 }
 
-type PostTransferTask403JSONResponse struct {
+type GetTransferTask403JSONResponse struct {
 	// Details further details, debugging information
 	Details *string `json:"details,omitempty"`
 
@@ -284,14 +1835,14 @@ type PostTransferTask403JSONResponse struct {
 	Message *string `json:"message,omitempty"`
 }
 
-func (response PostTransferTask403JSONResponse) VisitPostTransferTaskResponse(w http.ResponseWriter) error {
+func (response GetTransferTask403JSONResponse) VisitGetTransferTaskResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(403)
 
 	return json.NewEncoder(w).Encode(response)
 }
 
-type PostTransferTask500JSONResponse struct {
+type GetTransferTask500JSONResponse struct {
 	// Details further details, debugging information
 	Details *string `json:"details,omitempty"`
 
@@ -299,14 +1850,52 @@ type PostTransferTask500JSONResponse struct {
 	Message *string `json:"message,omitempty"`
 }
 
-func (response PostTransferTask500JSONResponse) VisitPostTransferTaskResponse(w http.ResponseWriter) error {
+func (response GetTransferTask500JSONResponse) VisitGetTransferTaskResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(500)
 
 	return json.NewEncoder(w).Encode(response)
 }
 
-type PostTransferTask503JSONResponse struct {
+type GetTransferTaskEventsRequestObject struct {
+	ScicatJobId string `json:"scicatJobId"`
+}
+
+type GetTransferTaskEventsResponseObject interface {
+	VisitGetTransferTaskEventsResponse(w http.ResponseWriter) error
+}
+
+type GetTransferTaskEvents200TexteventStreamResponse struct {
+	Body          io.Reader
+	ContentLength int64
+}
+
+func (response GetTransferTaskEvents200TexteventStreamResponse) VisitGetTransferTaskEventsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "text/event-stream")
+	if response.ContentLength != 0 {
+		w.Header().Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	w.WriteHeader(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(w, response.Body)
+	return err
+}
+
+type GetTransferTaskEvents400JSONResponse struct {
+	GeneralErrorResponseJSONResponse
+}
+
+func (response GetTransferTaskEvents400JSONResponse) VisitGetTransferTaskEventsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetTransferTaskEvents401JSONResponse struct {
 	// Details further details, debugging information
 	Details *string `json:"details,omitempty"`
 
@@ -314,42 +1903,160 @@ type PostTransferTask503JSONResponse struct {
 	Message *string `json:"message,omitempty"`
 }
 
-func (response PostTransferTask503JSONResponse) VisitPostTransferTaskResponse(w http.ResponseWriter) error {
+func (response GetTransferTaskEvents401JSONResponse) VisitGetTransferTaskEventsResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(503)
+	w.WriteHeader(401)
 
 	return json.NewEncoder(w).Encode(response)
 }
 
-type DeleteTransferTaskRequestObject struct {
+type GetTransferTaskEvents403JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response GetTransferTaskEvents403JSONResponse) VisitGetTransferTaskEventsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(403)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetTransferTaskEvents500JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response GetTransferTaskEvents500JSONResponse) VisitGetTransferTaskEventsResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetTransferTaskFilesRequestObject struct {
 	ScicatJobId string `json:"scicatJobId"`
-	Params      DeleteTransferTaskParams
 }
 
-type DeleteTransferTaskResponseObject interface {
-	VisitDeleteTransferTaskResponse(w http.ResponseWriter) error
+type GetTransferTaskFilesResponseObject interface {
+	VisitGetTransferTaskFilesResponse(w http.ResponseWriter) error
 }
 
-type DeleteTransferTask200Response struct {
+type GetTransferTaskFiles200JSONResponse TransferFiles
+
+func (response GetTransferTaskFiles200JSONResponse) VisitGetTransferTaskFilesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(200)
+
+	return json.NewEncoder(w).Encode(response)
 }
 
-func (response DeleteTransferTask200Response) VisitDeleteTransferTaskResponse(w http.ResponseWriter) error {
+type GetTransferTaskFiles400JSONResponse struct {
+	GeneralErrorResponseJSONResponse
+}
+
+func (response GetTransferTaskFiles400JSONResponse) VisitGetTransferTaskFilesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetTransferTaskFiles403JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response GetTransferTaskFiles403JSONResponse) VisitGetTransferTaskFilesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(403)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type GetTransferTaskFiles500JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response GetTransferTaskFiles500JSONResponse) VisitGetTransferTaskFilesResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(500)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type RetryTransferTaskRequestObject struct {
+	ScicatJobId string `json:"scicatJobId"`
+}
+
+type RetryTransferTaskResponseObject interface {
+	VisitRetryTransferTaskResponse(w http.ResponseWriter) error
+}
+
+type RetryTransferTask200JSONResponse TransferItem
+
+func (response RetryTransferTask200JSONResponse) VisitRetryTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(200)
-	return nil
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type RetryTransferTask400JSONResponse struct {
+	GeneralErrorResponseJSONResponse
+}
+
+func (response RetryTransferTask400JSONResponse) VisitRetryTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(400)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type RetryTransferTask401JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
+}
+
+func (response RetryTransferTask401JSONResponse) VisitRetryTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(401)
+
+	return json.NewEncoder(w).Encode(response)
 }
 
-type DeleteTransferTask400JSONResponse struct {
-	GeneralErrorResponseJSONResponse
+type RetryTransferTask403JSONResponse struct {
+	// Details further details, debugging information
+	Details *string `json:"details,omitempty"`
+
+	// Message the error message
+	Message *string `json:"message,omitempty"`
 }
 
-func (response DeleteTransferTask400JSONResponse) VisitDeleteTransferTaskResponse(w http.ResponseWriter) error {
+func (response RetryTransferTask403JSONResponse) VisitRetryTransferTaskResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(400)
+	w.WriteHeader(403)
 
 	return json.NewEncoder(w).Encode(response)
 }
 
-type DeleteTransferTask401JSONResponse struct {
+type RetryTransferTask409JSONResponse struct {
 	// Details further details, debugging information
 	Details *string `json:"details,omitempty"`
 
@@ -357,14 +2064,23 @@ type DeleteTransferTask401JSONResponse struct {
 	Message *string `json:"message,omitempty"`
 }
 
-func (response DeleteTransferTask401JSONResponse) VisitDeleteTransferTaskResponse(w http.ResponseWriter) error {
+func (response RetryTransferTask409JSONResponse) VisitRetryTransferTaskResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(401)
+	w.WriteHeader(409)
 
 	return json.NewEncoder(w).Encode(response)
 }
 
-type DeleteTransferTask403JSONResponse struct {
+type RetryTransferTask429JSONResponse QuotaExceeded
+
+func (response RetryTransferTask429JSONResponse) VisitRetryTransferTaskResponse(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(429)
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+type RetryTransferTask500JSONResponse struct {
 	// Details further details, debugging information
 	Details *string `json:"details,omitempty"`
 
@@ -372,14 +2088,14 @@ type DeleteTransferTask403JSONResponse struct {
 	Message *string `json:"message,omitempty"`
 }
 
-func (response DeleteTransferTask403JSONResponse) VisitDeleteTransferTaskResponse(w http.ResponseWriter) error {
+func (response RetryTransferTask500JSONResponse) VisitRetryTransferTaskResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(403)
+	w.WriteHeader(500)
 
 	return json.NewEncoder(w).Encode(response)
 }
 
-type DeleteTransferTask500JSONResponse struct {
+type RetryTransferTask503JSONResponse struct {
 	// Details further details, debugging information
 	Details *string `json:"details,omitempty"`
 
@@ -387,21 +2103,54 @@ type DeleteTransferTask500JSONResponse struct {
 	Message *string `json:"message,omitempty"`
 }
 
-func (response DeleteTransferTask500JSONResponse) VisitDeleteTransferTaskResponse(w http.ResponseWriter) error {
+func (response RetryTransferTask503JSONResponse) VisitRetryTransferTaskResponse(w http.ResponseWriter) error {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(500)
+	w.WriteHeader(503)
 
 	return json.NewEncoder(w).Encode(response)
 }
 
 // StrictServerInterface represents all server handlers.
 type StrictServerInterface interface {
+	// list facilities
+	// (GET /facilities)
+	GetFacilities(ctx context.Context, request GetFacilitiesRequestObject) (GetFacilitiesResponseObject, error)
+	// get the usage of the quotas
+	// (GET /quota)
+	GetQuota(ctx context.Context, request GetQuotaRequestObject) (GetQuotaResponseObject, error)
+	// list transfers
+	// (GET /transfer)
+	GetTransferTasks(ctx context.Context, request GetTransferTasksRequestObject) (GetTransferTasksResponseObject, error)
 	// request a transfer task
 	// (POST /transfer)
 	PostTransferTask(ctx context.Context, request PostTransferTaskRequestObject) (PostTransferTaskResponseObject, error)
+	// request a transfer task for several datasets
+	// (POST /transfer/batch)
+	PostBatchTransferTask(ctx context.Context, request PostBatchTransferTaskRequestObject) (PostBatchTransferTaskResponseObject, error)
+	// stream the status changes of transfers
+	// (GET /transfer/events)
+	GetTransferEvents(ctx context.Context, request GetTransferEventsRequestObject) (GetTransferEventsResponseObject, error)
+	// request the retrieval of a dataset
+	// (POST /transfer/retrieval)
+	PostRetrievalTask(ctx context.Context, request PostRetrievalTaskRequestObject) (PostRetrievalTaskResponseObject, error)
+	// validate a transfer request without starting it
+	// (POST /transfer/validate)
+	ValidateTransferTask(ctx context.Context, request ValidateTransferTaskRequestObject) (ValidateTransferTaskResponseObject, error)
 	// cancels and/or deletes transfer entry
 	// (DELETE /transfer/{scicatJobId})
 	DeleteTransferTask(ctx context.Context, request DeleteTransferTaskRequestObject) (DeleteTransferTaskResponseObject, error)
+	// get the status of a transfer
+	// (GET /transfer/{scicatJobId})
+	GetTransferTask(ctx context.Context, request GetTransferTaskRequestObject) (GetTransferTaskResponseObject, error)
+	// stream the status changes of a transfer
+	// (GET /transfer/{scicatJobId}/events)
+	GetTransferTaskEvents(ctx context.Context, request GetTransferTaskEventsRequestObject) (GetTransferTaskEventsResponseObject, error)
+	// get the outcome of each file of a transfer
+	// (GET /transfer/{scicatJobId}/files)
+	GetTransferTaskFiles(ctx context.Context, request GetTransferTaskFilesRequestObject) (GetTransferTaskFilesResponseObject, error)
+	// retry a failed or cancelled transfer
+	// (POST /transfer/{scicatJobId}/retry)
+	RetryTransferTask(ctx context.Context, request RetryTransferTaskRequestObject) (RetryTransferTaskResponseObject, error)
 }
 
 type StrictHandlerFunc = strictgin.StrictGinHandlerFunc
@@ -416,6 +2165,83 @@ type strictHandler struct {
 	middlewares []StrictMiddlewareFunc
 }
 
+// GetFacilities operation middleware
+func (sh *strictHandler) GetFacilities(ctx *gin.Context) {
+	var request GetFacilitiesRequestObject
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetFacilities(ctx, request.(GetFacilitiesRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetFacilities")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetFacilitiesResponseObject); ok {
+		if err := validResponse.VisitGetFacilitiesResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetQuota operation middleware
+func (sh *strictHandler) GetQuota(ctx *gin.Context) {
+	var request GetQuotaRequestObject
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetQuota(ctx, request.(GetQuotaRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetQuota")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetQuotaResponseObject); ok {
+		if err := validResponse.VisitGetQuotaResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetTransferTasks operation middleware
+func (sh *strictHandler) GetTransferTasks(ctx *gin.Context, params GetTransferTasksParams) {
+	var request GetTransferTasksRequestObject
+
+	request.Params = params
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetTransferTasks(ctx, request.(GetTransferTasksRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetTransferTasks")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetTransferTasksResponseObject); ok {
+		if err := validResponse.VisitGetTransferTasksResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
 // PostTransferTask operation middleware
 func (sh *strictHandler) PostTransferTask(ctx *gin.Context, params PostTransferTaskParams) {
 	var request PostTransferTaskRequestObject
@@ -451,6 +2277,128 @@ func (sh *strictHandler) PostTransferTask(ctx *gin.Context, params PostTransferT
 	}
 }
 
+// PostBatchTransferTask operation middleware
+func (sh *strictHandler) PostBatchTransferTask(ctx *gin.Context, params PostBatchTransferTaskParams) {
+	var request PostBatchTransferTaskRequestObject
+
+	request.Params = params
+
+	var body PostBatchTransferTaskJSONRequestBody
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.Status(http.StatusBadRequest)
+		ctx.Error(err)
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.PostBatchTransferTask(ctx, request.(PostBatchTransferTaskRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "PostBatchTransferTask")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(PostBatchTransferTaskResponseObject); ok {
+		if err := validResponse.VisitPostBatchTransferTaskResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetTransferEvents operation middleware
+func (sh *strictHandler) GetTransferEvents(ctx *gin.Context) {
+	var request GetTransferEventsRequestObject
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetTransferEvents(ctx, request.(GetTransferEventsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetTransferEvents")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetTransferEventsResponseObject); ok {
+		if err := validResponse.VisitGetTransferEventsResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// PostRetrievalTask operation middleware
+func (sh *strictHandler) PostRetrievalTask(ctx *gin.Context, params PostRetrievalTaskParams) {
+	var request PostRetrievalTaskRequestObject
+
+	request.Params = params
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.PostRetrievalTask(ctx, request.(PostRetrievalTaskRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "PostRetrievalTask")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(PostRetrievalTaskResponseObject); ok {
+		if err := validResponse.VisitPostRetrievalTaskResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// ValidateTransferTask operation middleware
+func (sh *strictHandler) ValidateTransferTask(ctx *gin.Context, params ValidateTransferTaskParams) {
+	var request ValidateTransferTaskRequestObject
+
+	request.Params = params
+
+	var body ValidateTransferTaskJSONRequestBody
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.Status(http.StatusBadRequest)
+		ctx.Error(err)
+		return
+	}
+	request.Body = &body
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.ValidateTransferTask(ctx, request.(ValidateTransferTaskRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ValidateTransferTask")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(ValidateTransferTaskResponseObject); ok {
+		if err := validResponse.VisitValidateTransferTaskResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
 // DeleteTransferTask operation middleware
 func (sh *strictHandler) DeleteTransferTask(ctx *gin.Context, scicatJobId string, params DeleteTransferTaskParams) {
 	var request DeleteTransferTaskRequestObject
@@ -479,28 +2427,201 @@ func (sh *strictHandler) DeleteTransferTask(ctx *gin.Context, scicatJobId string
 	}
 }
 
+// GetTransferTask operation middleware
+func (sh *strictHandler) GetTransferTask(ctx *gin.Context, scicatJobId string) {
+	var request GetTransferTaskRequestObject
+
+	request.ScicatJobId = scicatJobId
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetTransferTask(ctx, request.(GetTransferTaskRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetTransferTask")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetTransferTaskResponseObject); ok {
+		if err := validResponse.VisitGetTransferTaskResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetTransferTaskEvents operation middleware
+func (sh *strictHandler) GetTransferTaskEvents(ctx *gin.Context, scicatJobId string) {
+	var request GetTransferTaskEventsRequestObject
+
+	request.ScicatJobId = scicatJobId
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetTransferTaskEvents(ctx, request.(GetTransferTaskEventsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetTransferTaskEvents")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetTransferTaskEventsResponseObject); ok {
+		if err := validResponse.VisitGetTransferTaskEventsResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// GetTransferTaskFiles operation middleware
+func (sh *strictHandler) GetTransferTaskFiles(ctx *gin.Context, scicatJobId string) {
+	var request GetTransferTaskFilesRequestObject
+
+	request.ScicatJobId = scicatJobId
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.GetTransferTaskFiles(ctx, request.(GetTransferTaskFilesRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetTransferTaskFiles")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(GetTransferTaskFilesResponseObject); ok {
+		if err := validResponse.VisitGetTransferTaskFilesResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
+// RetryTransferTask operation middleware
+func (sh *strictHandler) RetryTransferTask(ctx *gin.Context, scicatJobId string) {
+	var request RetryTransferTaskRequestObject
+
+	request.ScicatJobId = scicatJobId
+
+	handler := func(ctx *gin.Context, request interface{}) (interface{}, error) {
+		return sh.ssi.RetryTransferTask(ctx, request.(RetryTransferTaskRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "RetryTransferTask")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		ctx.Error(err)
+		ctx.Status(http.StatusInternalServerError)
+	} else if validResponse, ok := response.(RetryTransferTaskResponseObject); ok {
+		if err := validResponse.VisitRetryTransferTaskResponse(ctx.Writer); err != nil {
+			ctx.Error(err)
+		}
+	} else if response != nil {
+		ctx.Error(fmt.Errorf("unexpected response type: %T", response))
+	}
+}
+
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/+RXTY/bOA/+K4TeF+gukCbT7faSW7v9QLYL7KDT2yAHRaZjzciSK9IzMAb57wtKdpwP",
-	"T79mb3tTFJHi8/AhKT8oE+omePRMavmgIlITPGH68QE9Ru3exRjip/4P2TfBM3qWpW4aZ41mG/zihoKX",
-	"PTIV1lpWTQwNRrbZXYGsreuXZKJtxEwtVdlGrjBCf2AGBW7a7db6LVhfhlgn/2qmuGtQLRVxtH6rdjNV",
-	"I5He4rlLrhBQ4obhyJn1br8TNjdoWO1k69iNhm3moHc20JOsM86E5711+Dl8jtpTiXE6nNI6BA7A/SnQ",
-	"BI2ODKEEPe5G/NIisZqdsGfpqqud9bfn3qlBY0uLBPcVJia5spQvtAQaqLfcI96E4FB7obDRXE0HLP9I",
-	"cEPwM7AMlSYBsUGI6DTbu4ypQig0a0IGCm00CGVwBcZJ2gWijVio5XW+fnaAbn2elpkiNG203F0J5ZmP",
-	"KyO6+4jd6zYDsBJ3hTrf6nUtPq6M/UPz89eXq+cfsRuj0Y2V3ynlorFzBj4hMby+XEEZYsL3wYVNSzAk",
-	"Ga4w3lmDc1gxtIQEOSLgcIuekpluuULPfYHM5XrLTu5/xJlcqGbqDiPlKF7ML+YXkqbQoNeNVUv1cn4x",
-	"f6ly4hITCz7QXROIz8GsGLRz4T6H1UtM6msvO9Z0S6C32npi0JCJy2BGiMID9aFqY0LrGUzwpd22EQu4",
-	"t1wdnnlGIO60N1J/ouZExKpQS3UZiAf4nzXdJkRR18gYSS2vpxRpC2GztBhB8juIc1CcNtZZliwnLXxp",
-	"MXajFPKp9+OhUYYcW5wd9K2T9tSbiNBbQqnb7GtK3I8WkvW5ShLxiQYwwTk0adm7HrS2z+g0FHHy7wA5",
-	"COe70dhiIH4o+Q0eakkieSQFqUIubfEDQcs9vRgvV2+/5+ZTFOt8GRK/CUX3hAEmTfAvm+vLMtZp8/8R",
-	"S7VU/1uMg3TRT4bFyVgYJ46OUXePjaBjZtLGwVD+7eLiCRBuwkaq7ysk34QNjBne94ebsPlmL8/O1xNz",
-	"lFpjkKhsneuAWEfG4nDoSfcRen7P4KY43ZOwmHyWJOMXTzF++fPGr54S9qufv1kmY1vXOnZqOWj8jNaZ",
-	"Yr2Vjqr2XWUtlvuxsXjIdfmnpG+XxeGQJ15V/QwRYbQk/gMYae7u8FLti0WQx5z4SGdFUmUMdW7WeUrK",
-	"HnXEWJ9NhrfJ8kdmgw9ciSD7rtO/KY6aTgL31bbzzeb3zuuNQ1oUltIiQ5T+k8CdAZvDmw4KLHXrWN5O",
-	"zwh60wJ+KbUj/HX+aINPCZgIb/9yy23tvDEcx5xDDB7uNYHRMVosILQMhxX536y8o+LJMqZj8dIoavSc",
-	"8jNVSgfv06TNk5fp9VpS1Rue5ufvQfmU39NYSFEdvxtHWci+OhfmtBMZkXsANDrhcRydOnrff4iF0aG8",
-	"SIZvoOOPsd5dEAu1W+/+CQAA//+vS5RuSw4AAA==",
+	"H4sIAAAAAAAC/+w9XXPjNpJ/BcW7Ku9WMbbzcVe1fpvJzKS8u3dx4tm7h5QfILIlYUwBCgBao0v5v181",
+	"GiBAEpTo0STxbvxGkfhoNPq7G9AvRaU2WyVBWlNc/VJoMFslDbgf34EEzZu3Wiv9o/+A7yslLUiLj3y7",
+	"bUTFrVDy4oNREt+Zag0bjk9brbagraDharBcNP7RVFpssVtxVSxbbdegmW9QshoW7Wol5IoJuVR648Yv",
+	"ysLut1BcFcZqIVfFY1lswBi+gvGQdg0MEG4Wmox6P3Zv1OIDVLZ4xFf9YThbEQ78YAE9rjet063nDbfc",
+	"gH2v3msuzRL0GCLOamrErGLWN2PcsC3Xlqkl42zBbbWO3zT83IKxRTnA41I08HdhbH7V+NXgePgjM2Xp",
+	"PuzWqomfhek+a6iZWDJhzwyTyjID9py9kgw2W7tnjTCutQbEGNRFWQgLGwfWv2tYFlfFv11EirrwKLp4",
+	"JxpIkIM7J+Q19fyy2weuNd8XDrNIUzeizi/xthLfcsturt8M1pndZESj0FAXVz8lA9+Ndr/sdtGDiUCb",
+	"PASqtZXaAE4PvFo7pAdYqlZrkJZ916hFa5jl5p62NwLZ38+Va/iem/trt+ARlffQMf56L7ZbqA9Rg11z",
+	"y3aggfnGbAEVbw0tACnbzN3JWxoAcVM8jncuIaN5ACUdmGmrCoxZtk2znwvP+9g/D9MkBZR9xPdhj2id",
+	"QShIyPnVGsttRxgdYztqMEKuEhZ077oWH9RiRCaLvQXzvo9fD5mQFlbEV0suGkKFmdoAbSxbwi6A9VuT",
+	"iJvvnQMzD6FsNwvC0lNAy6ACe79XljcTqBLNDIQmKuapjGl500B9K2SV0VAGX7PdGmSQFLUCI88s22q1",
+	"0mAM2wm77lNOWLiwXrAIw4TklRUPwJRmW/xal0zJZs8MWLZbo2TqjSEM85AVZUHatbgqam7hCys2kFOz",
+	"SMbtbFa8pdaHOM8PmOOtd7wSjbD7nAZd+m9EEhWXzPJ7IA0qZLdEUzLeKLmKCGwN6DPDtFitEXOKERJH",
+	"PFZx+RregLFCks0xAmK3BmerhFHZhu+Dpo4A4BR2LUwHccTqQqkGuMSlutluVasr+OSJllptZkwl+WbC",
+	"ShI1SCuWAjTDRp1UGI02oVLdyP21lGM8Zne6bxRMqotpg+moqSTM7X7TCHk/Ht1socJlGxYxLQxNKAwK",
+	"aN8zh84tt+s8wPgllawlE5atuaOIBTANDXfM6ggkin/jMMeWqqlBH8W5m75MVpdD73datdsfWmV5HtIW",
+	"xVoA9Wds58xGLpnaSdBshf3JWmzEBiUO14DyyVmMrtv+TANrpfsM9Qj7NRfN/vXe+l8jCJxGG2rH7oWb",
+	"P+wsWqWSYOHGsq++YWvVIqPv1qJaOwMW1a34PxiYhIa10opmMAXImnFZswhF3iLhSwt6x3VtUlkppP3P",
+	"b7Iqx8GcVQYb/vFNDx0zRtvwj/+lpF0/udcPLbRQB84yE4ptMPSAPVAWcMPiHpZsqfQAj/nd+fqS1Xw/",
+	"E2U/j2EdU8oEcdCmcWHD9G6wzDQD/nG9i/HcZUqyAwzlWMxx19uPFUA9Zcw4xiJAeSe+d6ptagau49gd",
+	"CDQ0HoxWvVsrEwamkRbgB0Pd7722brciawdVklPxjofnUtgBk+jnaYGToGIEdlEWINsNbg5ZM+mmfNo2",
+	"RZg0bLiQ+COjZb0hYaxoGpLQgaTReujoClc1i567/hOT4XB9OuB1HdSBk8kl61vBCfHraTEnJEmyeVC2",
+	"hgA82nTAOTGaElBChONHTJef4n2Sef4xHb6Z0E+dPYTyO/l9Zog9TJ6h3NMs9yXRmxnvBac6NsI/DGg/",
+	"wAB9nvs8RDmspM5TzgImc96JPZPzh7gkl2isjKM1duOtlxGjuJ7fqnpiR7yf4lqxCpuVE2O8iaG+UQMy",
+	"dSZgGPoMsW0KXQ5xJ0RtjjjegcVmU1A2jnQgWHJdH8dE0raMEB1CRIhK5KII007xvChDpYFbqF/1NcZB",
+	"H9LDfCPqPiJHDYdYOhX9Dg+5cYHXjZATtI7LIPPPm5hDH7risgL0op0gwrCLocApWvtoJiNkDdgnuNkJ",
+	"i6Ze8Kjdv1AEZauF0n6dc2ILN6F9sBxvlBF5T915Y/7rKASX2otjDwRNSsw+kM9m2sVGWAtOS5MMLJmx",
+	"XLs23LIvz9n3BwIuOJo5ZqGWhQarBTzwZryUNBaQ+LyuPZgYWqZoQBInEaQnthoehGpNs+95N1ZlnVsS",
+	"ugcp8NMCQidIvAMBozDL9w5bU6KfPoatDrF53/WcvU/akLEefV0XY8JeNSx521jDKiWXYtUiDoOVveWC",
+	"GI7QJsCUnYcZmjvOdtamBlx0RdbgA29aiNMy3jRql3OnjwusxT4nrXz4IQiksifXhJ2QZufs2pnGZxaZ",
+	"oOHWESAnMt7wj2LTbljdrWoOSkK0qrOAz9n/CrtWrUUa9ssrM8DnZnRjufipsKwB/gCmx2FzZS6iJAlV",
+	"vf1odcaBoXZJwNyb8onMph2sFWIMPgpjQxsTwmJjXgNZ6f3Wosoaz+k/dnY+q9ZcSmiGAis7Mu7h95I8",
+	"VJLwo/GxyUBUITqHyyK/TZjOWWNCGgvcmd8arN4jLnMwNHwBTZ5c3achO3Jzn9ujrQYD+gHeiw0YyzcZ",
+	"7zg0IVJRtVj6rDTxRS/VMd65vBi8F1sKaE7hD1sMUygdw2hEUI8AUrQh6p2GObaNZi+rv8NDDo0uyN9t",
+	"3QCODBkmCy6Z0j6OgM5kmUOZZtUaqnuDPCeW3vEOTrobGF9g96IsNp6/QpesH/4AWiz334YmowXRdxKZ",
+	"YeoBqTv5gqvMIOvxgHK4SeyMjJngv1LCIG/vZawAEvCaV/cUd9Eb3qAN2OXNb9WmG1yAk7JEGiT9ofP7",
+	"N4AGm1NP5BuSRurg6jspwTzpGTHCMM2FgZqpB9BuD0tmFFFDL37WqF0c2eu5NWA8hlf3KL3hAXSy143a",
+	"FWVByyvKYi1W66IsWr0CabP7PND7V790Q3kkkrzwy0gzr/QzSU8JKcyaHsneLYtOVRVlIeQDb0TNRubB",
+	"GJj/wYZ82lTUYNrG5RUefEs07o6nGJCrvlVNAxUOdl0fdJ2rriETXfQilbXTaRdyD24OJx7SlEIXY+ub",
+	"fIFq0lkjWLl5CfPEtrOdsIhu1zHngH1Qiyl0fVAL5n1MtGm4ZMB1I+I2xNSeC1SLGjZbZUFWe3YP+5AW",
+	"8I2HgtajRoNttUylcjTqpXIGt5L5fKiT56ftesj4HNjwfqTkcK4p7LrfXT/64Y11hD7tbfCm8XKYbblx",
+	"eeUgT3qITaqHyErYQpBtrqID6jh5KqxTY59AGdDaIWtfz42RHSgweXqA7JNjV8PBc0uLYcMn5etc9POk",
+	"PN0w4j4vBYPTRqeFYvRMIc+sVM8g7OemXo0nOxJzHoKXw91Q3GTIwpEyAUxUhq5EEA0PXf+ZmeUqPw2O",
+	"labRXbOSKenenPVd67OSnSFh9H4TH+MjWQL4lIi3vwE1a6nuEvCHwz09KMvDqEhn+OzdWnwMWv8M9+ks",
+	"1BGe5ZXNb1usqYGbGKfBDWo1HE2E0y7EocekgUwLVYurvkXVRLt362pR/gb7Vy2xskA41sAp+07VEgXV",
+	"GX7x6ub6i79BIqH5VuBvVy+Kax6v6Ecwlr26ue58Ya8JAhGzW9APogLnY7cGDCOImFX3II3rxlu7Bmm9",
+	"UX6O0wuLEq+YGAwnLJyVbQiKL88vzy8R82oLkm9FcVV8fX55/nVBBQwOExfRO8efK8iUlTbCWMPQKtzH",
+	"0NK9VDsZ7FdDEPTqbrIFLFR3w7xP77UUl/WFx1NqlfQYsXCr0Dyo2+I7sO8i6GW/cvmry8snFSrPK2L1",
+	"S8/UGI4Kh0kaG9uPf2DPby6/nJqoW8JFtvL6sSz+4/LyUzsjI7SbDdd7v6MpXGVh+cogRzm7p7jD5hdd",
+	"LjdLFWQ+YfkMt3GT0SVyyZVcai7UxjZECNREaK/NyqQcg2tglWqlDf4MZ1o1zmWu+d7NEt/EeoMRifzg",
+	"s5QnUcchokiymBNkkNHbz4YOVmDZFIx5mrBJudYBYdEztz+ohY9KdGSidtKns7llC3BSw6qgJ0eEU6JR",
+	"Z0H7+OSWr1BIQJ3b8iAWsa7XOFGn+QasszV+ysZQHDck0X9yLahO0mKwv1PfnfuK2tT7r/iYOrD423uw",
+	"+BhcWPfs7A7Svp0be+b82OIK8xl6H7UPTV6UCSGOlOGc5QwchLi6rainpk5LNU+bPVufmJ2zn3U4eWKr",
+	"Zk2b2l+nTxqLRyjETnhWQpJjRjGyHBghoYq9emDMiWI/FbYFLJWGJwP32nX7DNCl4fxcmYtV3jcv2VeX",
+	"6P/7xMsEeKECJYLlBy+uvry8dEdN/K9cecvh/GkPKIz3UhWcF0Bk6Gap+V5s+yAFIC4zQNydqKD6rokN",
+	"Cdvxwtyn7PI2ePYoeEMkbPM5YZt6bk86opFPxA+zfg74dJq7I+aVjVXfG2Us01CBtHTOgRTt5acr2udj",
+	"rdmk/C0o5vCuuMMsicodCbu2lE8knyKN23SJA9STjK+4kMYyHk5XOU8k+ieJlc945UyzNOcXw3HU5sy4",
+	"uBrquJGOvlGmp6SP6egDVerjINos1RIJzuoWUjYdJsioSzgxwM0ol3dYyrkI3cGAaxh6WFs7T12dsJBs",
+	"+uvIakQ9tCYWkNKShjkWxVygDx71y858dBUco8OBmPZB3HmuKJmGLYTAfz7QjN2D45MJVMcDVkrCwcjy",
+	"cJ+HsYfrGPLxwYdp0+SuK7t8rer9CZrjX/9kqYpFInNUVqgpOalSKX/QuM8Fj5/VAjiQWfEMhXQbuXlQ",
+	"/ng45kaD57RyGl4nikdDuK9pfl+N/M3l16d0/ssJnb/6y+eNOXTHDibMo9xZA8Z9MUcawlc6/EzO/QzY",
+	"3JxozmDnrz+LLRSWNSSrrFGUBiwu3Ol6xytPNJQGR3cNBkJ506GGLcDuAGRUEuPCp3P2FmNiE1JxsXcH",
+	"OjGemlTClGzROpj8XmxcUMyXG7BW1qDjMeLI1llz6zWu/Q9ic02APZFlf1ZG1u9vnvSNE/OsrJNPLgR/",
+	"9uZBql4P1Pb/kcyG3vjORX2xHF4sh1/BcnDqfqjTZ5gT8BDuTMqmQYzVwDc+buIi+a56d0UelNPqVCeR",
+	"hhhPSZE4RQn6AfQXBqRlBF9yUCFoCqG7K2oIMG+cuA5MGKc1YwC7A3/A9FTeLqxxSAsnsNNz+pz99fb7",
+	"/2YgK1VDzXphwAN5m7eE2KOSzcJHS7vwBSG7zwZDaZS524m6kU03RhyVkBnL94apLcjkIHnVCGxZC1Mp",
+	"KaGyzyej59eUJ7sjMcQeffeOo8wzmV0HMk8+9UAKWSnCxIoCV5RqFevd/uFZZN03scL9HkpS+Wxibnvg",
+	"oO6ODWjoxTS3rWXCBqL2IYoN12hrIy3rai0e+KIBpmQFcbysuf1jwN2JpnZvxV0Cb1DUZlXZHeU4G670",
+	"17TOw0w96Pykp1jqR+ewamJZzzM6mtLKbxkbTefNGuW/m+HYyZZfJ+AUh3+JOL3YjZ/LbuwT7uBGv2Pa",
+	"1NeXwrQy1a000ZH3ddfcpJGogeVasp0/PBfrUhiXe7v2Z1d9MElpigHQEJEhXXIPDzeaNHQVNJXThAtV",
+	"70Ox3rAklnRlGm3QYFSDmiemt4yv17HrnAEZiwWpuDAp0SUi2oSjl2ETsIJmpHJ98S+8BLiee4BrQk/S",
+	"OevfI4uYm3nuJqQnTkYhkcgk7nN3HIE3Gnjt6h/reaG6WIH+kih8SRQ+/oq1rJmjcpOqPhyW6x+eSO5j",
+	"440JZVxQ08FiowYHI4w/ifFsvGe/EsicAInKNgRUxBzF/wvJrL+iDftI7NKAzZ1bIk86nqxR/lB+Couv",
+	"lE8Oo6PY6E7V01zundmbXJzljev5FEUplbMngvjxVw/2BLJb3EGRfFSkvpXoW5uLWhj3QEtELLvFjRZ2",
+	"zl53ZYEliQrftWZ/WvLGwJ/PJ5Wf24AMePFw2oR7lLkSALUtyvWKay2gZmSORbfkn9j7+FxMRWRs+sQb",
+	"xT0DafU+z0rl4aMH6XXb3VXL/ducSlapzcLdQsYG/miXOmvwQs7+Vc0YGHap1o7RD9aZz7E256RQPiOL",
+	"3f0GioIqOvMqYrQv6XJfuKI7ehGj673DTk/SKycmI3pUOC+PIKz5/bMIFOWlxTGQtUmmI9uZoHEQ47lg",
+	"yZsAx59mHMz48zG271IV/3zM/+tmT174+3Ai5gRWX4b7DadPX9EZBUgrjjJ6cXBhTMrR6V9XTJ2f7263",
+	"UhIME3b6armSwlhbpb1vS8MT9zqAXWUTuXehDrqrrqJbalKIdnS/XryQZQ9HFfQ7f2fMH1FL09ozPJya",
+	"UT2f/TOq6WekaY9f//lkZnS3X03HdNPUPoRbB5ROblvryDyjh/FERhmUl9nLKn53HIsMZ7J/4RGCTJXa",
+	"CqiJ0Sh0XQ9uOezfIdSIe2ASdo6nnYSgr/gq4UDTq0Rc7GP4OHJPiATcA3ggRd3RV0j/unlGrIvZ0/2L",
+	"dT1pXfeksL93IyQn9mMDK3A5CnjcSGeQvyTFXpJipyXFkNZ4VqYdkabJjRyOlwd3cfx093jXdRxy+vdB",
+	"UBj6Jw2qGenflBGZG98Xj+W8QZz/kRbI+EESXTgc6J2/CkXFAdF8CX8Z178OxQ9HZ+kf7x7/fwBAHp/x",
+	"em8AAA==",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
@@ -6,8 +6,10 @@ import (
 	"text/template"
 
 	"github.com/SwissOpenEM/globus"
+	"github.com/SwissOpenEM/globus-transfer-service/internal/config"
 	"github.com/SwissOpenEM/globus-transfer-service/internal/serviceuser"
 	"github.com/SwissOpenEM/globus-transfer-service/internal/tasks"
+	"github.com/SwissOpenEM/globus-transfer-service/jobs"
 )
 
 //go:generate oapi-codegen --config=cfg.yaml openapi.yaml
@@ -20,18 +22,26 @@ type ServerHandler struct {
 	srcGroupTemplate      *template.Template
 	dstGroupTemplate      *template.Template
 	dstPathTemplate       DestinationTemplate
-	taskPool              tasks.TaskPool
-	addTaskMutex          *sync.Mutex
+	retrievalPathTemplate DestinationTemplate
+	transferOptionRules   []config.TransferOptions
+	// the templates of the groups allowed to request the restricted priorities
+	priorityGroupTemplates map[jobs.Priority]*template.Template
+	quotas                 config.Quotas
+	taskPool               tasks.TaskPool
+	addTaskMutex           *sync.Mutex
+	submissionLocks        *submissionLocks
 }
 
 type ScicatDataset struct {
 	OwnerGroup   string `json:"ownerGroup"`
 	SourceFolder string `json:"sourceFolder"`
+	// the total size of the files of the dataset in bytes
+	Size int64 `json:"size"`
 }
 
 var _ StrictServerInterface = ServerHandler{}
 
-func NewServerHandler(globusClient globus.GlobusClient, scopes []string, scicatUrl string, scicatServiceUser serviceuser.ScicatServiceUser, facilityCollectionIDs map[string]string, srcGroupTemplateBody string, dstGroupTemplateBody string, dstPathTemplateBody string, taskPool tasks.TaskPool) (ServerHandler, error) {
+func NewServerHandler(globusClient globus.GlobusClient, scopes []string, scicatUrl string, scicatServiceUser serviceuser.ScicatServiceUser, facilityCollectionIDs map[string]string, srcGroupTemplateBody string, dstGroupTemplateBody string, dstPathTemplateBody string, retrievalPathTemplateBody string, transferOptionRules []config.TransferOptions, priorityGroupTemplateBodies map[string]string, quotas config.Quotas, taskPool tasks.TaskPool) (ServerHandler, error) {
 	// create server with service client
 	var err error
 	if !globusClient.IsClientSet() {
@@ -53,16 +63,40 @@ func NewServerHandler(globusClient globus.GlobusClient, scopes []string, scicatU
 		return ServerHandler{}, err
 	}
 
+	// retrieved datasets are laid out like transferred ones, unless specified otherwise
+	if retrievalPathTemplateBody == "" {
+		retrievalPathTemplateBody = dstPathTemplateBody
+	}
+	retrievalPathTemplate, err := NewDestinationTemplate(retrievalPathTemplateBody)
+	if err != nil {
+		return ServerHandler{}, err
+	}
+
+	err = validateTransferOptionRules(transferOptionRules)
+	if err != nil {
+		return ServerHandler{}, err
+	}
+
+	priorityGroupTemplates, err := parsePriorityGroupTemplates(priorityGroupTemplateBodies)
+	if err != nil {
+		return ServerHandler{}, err
+	}
+
 	return ServerHandler{
-		scicatUrl:             scicatUrl,
-		scicatServiceUser:     scicatServiceUser,
-		globusClient:          globusClient,
-		facilityCollectionIDs: facilityCollectionIDs,
-		srcGroupTemplate:      srcGroupTemplate,
-		dstGroupTemplate:      dstGroupTemplate,
-		dstPathTemplate:       dstPathTemplate,
-		taskPool:              taskPool,
-		addTaskMutex:          &sync.Mutex{},
+		scicatUrl:              scicatUrl,
+		scicatServiceUser:      scicatServiceUser,
+		globusClient:           globusClient,
+		facilityCollectionIDs:  facilityCollectionIDs,
+		srcGroupTemplate:       srcGroupTemplate,
+		dstGroupTemplate:       dstGroupTemplate,
+		dstPathTemplate:        dstPathTemplate,
+		retrievalPathTemplate:  retrievalPathTemplate,
+		transferOptionRules:    transferOptionRules,
+		priorityGroupTemplates: priorityGroupTemplates,
+		quotas:                 quotas,
+		taskPool:               taskPool,
+		addTaskMutex:           &sync.Mutex{},
+		submissionLocks:        newSubmissionLocks(),
 	}, err
 }
 
@@ -0,0 +1,286 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/SwissOpenEM/globus"
+	"github.com/SwissOpenEM/globus-transfer-service/jobs"
+	"github.com/gin-gonic/gin"
+)
+
+func (s ServerHandler) PostBatchTransferTask(ctx context.Context, request PostBatchTransferTaskRequestObject) (PostBatchTransferTaskResponseObject, error) {
+	ginCtx, ok := ctx.(*gin.Context)
+	if !ok {
+		return PostBatchTransferTask500JSONResponse{
+			Message: getPointerOrNil("context error"),
+		}, nil
+	}
+
+	// check facility id's and fetch collection id's
+	sourceCollectionID, ok := s.facilityCollectionIDs[request.Params.SourceFacility]
+	if !ok {
+		return PostBatchTransferTask403JSONResponse{
+			Message: getPointerOrNil("invalid source facility"),
+		}, nil
+	}
+	destCollectionID, ok := s.facilityCollectionIDs[request.Params.DestFacility]
+	if !ok {
+		return PostBatchTransferTask403JSONResponse{
+			Message: getPointerOrNil("invalid destination facility"),
+		}, nil
+	}
+
+	u, ok := ginCtx.Get("scicatUser")
+	if !ok {
+		return PostBatchTransferTask500JSONResponse{
+			Message: getPointerOrNil("no user was found"),
+		}, nil
+	}
+
+	// fetch scicat user
+	scicatUser, ok := u.(User)
+	if !ok {
+		return PostBatchTransferTask500JSONResponse{
+			Message: getPointerOrNil("invalid user in context"),
+			Details: getPointerOrNil(fmt.Sprintf("type found: '%s'", reflect.TypeOf(u))),
+		}, nil
+	}
+
+	if request.Body == nil || len(request.Body.Datasets) == 0 {
+		return PostBatchTransferTask400JSONResponse{
+			GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
+				Message: getPointerOrNil("no datasets were sent with the request"),
+			},
+		}, nil
+	}
+
+	// fetch related datasets, which all have to belong to the same owner group as they're tracked by a single job
+	datasets := make([]ScicatDataset, len(request.Body.Datasets))
+	seenPids := map[string]bool{}
+	for i, datasetToTransfer := range request.Body.Datasets {
+		if seenPids[datasetToTransfer.ScicatPid] {
+			return PostBatchTransferTask400JSONResponse{
+				GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
+					Message: getPointerOrNil(fmt.Sprintf("the dataset '%s' is listed more than once", datasetToTransfer.ScicatPid)),
+				},
+			}, nil
+		}
+		seenPids[datasetToTransfer.ScicatPid] = true
+
+		if err := checkFileList(datasetToTransfer.FileList); err != nil {
+			return PostBatchTransferTask400JSONResponse{
+				GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
+					Message: getPointerOrNil(fmt.Sprintf("the file list of the dataset '%s' is invalid", datasetToTransfer.ScicatPid)),
+					Details: getPointerOrNil(err.Error()),
+				},
+			}, nil
+		}
+
+		dataset, err := fetchScicatDataset(s.scicatUrl, scicatUser.ScicatToken, datasetToTransfer.ScicatPid)
+		if err != nil {
+			notAccessibleErr := &DatasetNotAccessibleError{}
+			if errors.As(err, &notAccessibleErr) {
+				return PostBatchTransferTask400JSONResponse{
+					GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
+						Message: getPointerOrNil(fmt.Sprintf("the dataset '%s' does not exist or you don't have access rights to it", datasetToTransfer.ScicatPid)),
+						Details: getPointerOrNil(notAccessibleErr.Error()),
+					},
+				}, nil
+			}
+			return PostBatchTransferTask500JSONResponse{
+				Message: getPointerOrNil(fmt.Sprintf("failed to fetch the dataset '%s'", datasetToTransfer.ScicatPid)),
+				Details: getPointerOrNil(err.Error()),
+			}, nil
+		}
+
+		if i > 0 && dataset.OwnerGroup != datasets[0].OwnerGroup {
+			return PostBatchTransferTask400JSONResponse{
+				GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
+					Message: getPointerOrNil("all datasets of a batch transfer must belong to the same owner group"),
+					Details: getPointerOrNil(fmt.Sprintf("dataset '%s' belongs to '%s' instead of '%s'", datasetToTransfer.ScicatPid, dataset.OwnerGroup, datasets[0].OwnerGroup)),
+				},
+			}, nil
+		}
+		datasets[i] = dataset
+	}
+	ownerGroup := datasets[0].OwnerGroup
+
+	// check for required group memberships
+	srcGroup, err := executeGroupTemplate(s.srcGroupTemplate, request.Params.SourceFacility)
+	if err != nil {
+		return PostBatchTransferTask500JSONResponse{
+			Message: getPointerOrNil("group templating failed with source facility"),
+			Details: getPointerOrNil(err.Error()),
+		}, nil
+	}
+
+	dstGroup, err := executeGroupTemplate(s.dstGroupTemplate, request.Params.DestFacility)
+	if err != nil {
+		return PostBatchTransferTask500JSONResponse{
+			Message: getPointerOrNil("group templating failed with destination facility"),
+			Details: getPointerOrNil(err.Error()),
+		}, nil
+	}
+
+	missingGroups := missingGroups(scicatUser, srcGroup, dstGroup, ownerGroup)
+	if len(missingGroups) > 0 {
+		return PostBatchTransferTask401JSONResponse{
+			Message: getPointerOrNil("you don't have the required access groups to request this transfer"),
+			Details: getPointerOrNil(fmt.Sprintf("missing groups: '%v'", missingGroups)),
+		}, nil
+	}
+
+	serviceUserToken, err := s.scicatServiceUser.GetToken()
+	if err != nil {
+		return PostBatchTransferTask500JSONResponse{
+			Message: getPointerOrNil("service user login failed"),
+			Details: getPointerOrNil(err.Error()),
+		}, nil
+	}
+
+	// repeated requests for the same datasets are handled one at a time, so that only the first one starts them
+	idempotencyKey := ""
+	if request.Params.IdempotencyKey != nil {
+		idempotencyKey = *request.Params.IdempotencyKey
+	}
+	pids := make([]string, len(request.Body.Datasets))
+	for i, datasetToTransfer := range request.Body.Datasets {
+		pids[i] = datasetToTransfer.ScicatPid
+	}
+	unlock := s.lockSubmission(scicatUser.Profile.Username, idempotencyKey, pids, request.Params.DestFacility)
+	defer unlock()
+
+	if idempotencyKey != "" {
+		job, found, err := s.findIdempotentTransfer(serviceUserToken, scicatUser.Profile.Username, idempotencyKey)
+		if err != nil {
+			return PostBatchTransferTask500JSONResponse{
+				Message: getPointerOrNil("failed to look for the job of the idempotency key"),
+				Details: getPointerOrNil(err.Error()),
+			}, nil
+		}
+		if found {
+			if !isSameTransfer(job, pids, request.Params.SourceFacility, request.Params.DestFacility) {
+				return PostBatchTransferTask409JSONResponse{
+					Message: getPointerOrNil("the idempotency key was already used for a different transfer request"),
+					Details: getPointerOrNil(fmt.Sprintf("job id: '%s'", job.ID)),
+				}, nil
+			}
+			return PostBatchTransferTask200JSONResponse{
+				JobId: job.ID,
+			}, nil
+		}
+	}
+
+	// request the transfers
+	batchSize := int64(0)
+	for _, dataset := range datasets {
+		batchSize += dataset.Size
+	}
+	failed, unlockQueue := s.checkSubmission(serviceUserToken, submissionCheck{
+		pids:         pids,
+		destFacility: request.Params.DestFacility,
+		ownerUser:    scicatUser.Profile.Username,
+		ownerGroup:   ownerGroup,
+		size:         batchSize,
+	}, false)
+	defer unlockQueue()
+	if len(failed) > 0 {
+		return postBatchTransferTaskFailure(failed[0]), nil
+	}
+
+	options, err := s.transferOptions(request.Params.SourceFacility, request.Params.DestFacility, request.Body.Options)
+	if err != nil {
+		return PostBatchTransferTask400JSONResponse{
+			GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
+				Message: getPointerOrNil("the requested transfer options are not allowed"),
+				Details: getPointerOrNil(err.Error()),
+			},
+		}, nil
+	}
+
+	priority, err := s.transferPriority(scicatUser, request.Params.SourceFacility, request.Params.DestFacility, request.Body.Priority)
+	if err != nil {
+		unknownErr := &UnknownPriorityError{}
+		notAllowedErr := &PriorityNotAllowedError{}
+		if errors.As(err, &unknownErr) {
+			return PostBatchTransferTask400JSONResponse{
+				GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
+					Message: getPointerOrNil("the requested priority doesn't exist"),
+					Details: getPointerOrNil(err.Error()),
+				},
+			}, nil
+		}
+		if errors.As(err, &notAllowedErr) {
+			return PostBatchTransferTask403JSONResponse{
+				Message: getPointerOrNil("you don't have the right to request this priority"),
+				Details: getPointerOrNil(err.Error()),
+			}, nil
+		}
+		return PostBatchTransferTask500JSONResponse{
+			Message: getPointerOrNil("group templating failed with the requested priority"),
+			Details: getPointerOrNil(err.Error()),
+		}, nil
+	}
+
+	datasetList := make([]jobs.Dataset, len(datasets))
+	transfers := make([]globus.Transfer, len(datasets))
+	for i, datasetToTransfer := range request.Body.Datasets {
+		destPath, err := s.datasetDestinationPath(datasets[i], datasetToTransfer.ScicatPid, scicatUser.Profile.Username)
+		if err != nil {
+			return PostBatchTransferTask500JSONResponse{
+				Message: getPointerOrNil(fmt.Sprintf("couldn't template destination folder for the transfer of '%s'", datasetToTransfer.ScicatPid)),
+				Details: getPointerOrNil(err.Error()),
+			}, nil
+		}
+
+		datasetList[i] = jobDataset(datasetToTransfer.ScicatPid, datasets[i].Size, datasets[i].SourceFolder, destPath, datasetToTransfer.FileList)
+		transfers[i] = globusTransfer(sourceCollectionID, datasets[i].SourceFolder, destCollectionID, destPath, datasetToTransfer.FileList, options)
+	}
+
+	scicatJob, err := s.taskPool.SubmitTransfer(scicatUser.Profile.Username, ownerGroup, jobs.JobParams{
+		DatasetList:         datasetList,
+		SourceFacility:      request.Params.SourceFacility,
+		DestinationFacility: request.Params.DestFacility,
+		IdempotencyKey:      idempotencyKey,
+		TransferOptions:     &options,
+		Priority:            priority,
+	}, transfers)
+	if err != nil {
+		return PostBatchTransferTask500JSONResponse{
+			Message: getPointerOrNil(submissionFailure(err, "failed creating transfer job in SciCat")),
+			Details: getPointerOrNil(err.Error()),
+		}, nil
+	}
+
+	// return response
+	return PostBatchTransferTask200JSONResponse{
+		JobId: scicatJob.ID,
+	}, nil
+}
+
+// the response of PostBatchTransferTask to a failed check of the submission of its transfers
+func postBatchTransferTaskFailure(failed failedTransferCheck) PostBatchTransferTaskResponseObject {
+	switch failed.status {
+	case http.StatusConflict:
+		return PostBatchTransferTask409JSONResponse{
+			Message: getPointerOrNil(failed.message),
+			Details: getPointerOrNil(failed.details),
+		}
+	case http.StatusTooManyRequests:
+		return PostBatchTransferTask429JSONResponse(*failed.quota)
+	case http.StatusServiceUnavailable:
+		return PostBatchTransferTask503JSONResponse{
+			Message: getPointerOrNil(failed.message),
+			Details: getPointerOrNil(failed.details),
+		}
+	default:
+		return PostBatchTransferTask500JSONResponse{
+			Message: getPointerOrNil(failed.message),
+			Details: getPointerOrNil(failed.details),
+		}
+	}
+}
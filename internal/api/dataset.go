@@ -0,0 +1,195 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/SwissOpenEM/globus"
+	"github.com/SwissOpenEM/globus-transfer-service/jobs"
+)
+
+type DatasetNotAccessibleError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *DatasetNotAccessibleError) Error() string {
+	return fmt.Sprintf("response status '%d', body '%s'", e.StatusCode, e.Body)
+}
+
+// fetches a dataset from SciCat with the given token, so that only datasets the token's user
+// has access to are returned. Returns a DatasetNotAccessibleError if SciCat refuses the request.
+func fetchScicatDataset(scicatUrl string, scicatToken string, pid string) (ScicatDataset, error) {
+	datasetUrl, err := url.JoinPath(scicatUrl, "api", "v3", "datasets", url.QueryEscape(pid))
+	if err != nil {
+		return ScicatDataset{}, fmt.Errorf("couldn't create dataset request url: %w", err)
+	}
+
+	datasetReq, err := http.NewRequest("GET", datasetUrl, nil)
+	if err != nil {
+		return ScicatDataset{}, fmt.Errorf("couldn't generate dataset request: %w", err)
+	}
+	datasetReq.Header.Set("Authorization", "Bearer "+scicatToken)
+
+	datasetResp, err := http.DefaultClient.Do(datasetReq)
+	if err != nil {
+		return ScicatDataset{}, fmt.Errorf("couldn't send dataset request to scicat backend: %w", err)
+	}
+	defer datasetResp.Body.Close()
+
+	if datasetResp.StatusCode != 200 {
+		body, _ := io.ReadAll(datasetResp.Body)
+		return ScicatDataset{}, &DatasetNotAccessibleError{StatusCode: datasetResp.StatusCode, Body: string(body)}
+	}
+
+	datasetRespBody, err := io.ReadAll(datasetResp.Body)
+	if err != nil {
+		return ScicatDataset{}, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var dataset ScicatDataset
+	err = json.Unmarshal(datasetRespBody, &dataset)
+	if err != nil {
+		return ScicatDataset{}, fmt.Errorf("failed to unmarshal response body: %w", err)
+	}
+	return dataset, nil
+}
+
+// returns the groups out of requiredGroups that the user is not a member of
+func missingGroups(user User, requiredGroups ...string) []string {
+	missing := []string{}
+	for _, group := range requiredGroups {
+		if !slices.Contains(user.Profile.AccessGroups, group) {
+			missing = append(missing, group)
+		}
+	}
+	return missing
+}
+
+func (s ServerHandler) datasetDestinationPath(dataset ScicatDataset, pid string, username string) (string, error) {
+	return s.dstPathTemplate.Execute(datasetPathParams(dataset, pid, username))
+}
+
+// the path a dataset is retrieved to at the user's facility
+func (s ServerHandler) datasetRetrievalPath(dataset ScicatDataset, pid string, username string) (string, error) {
+	return s.retrievalPathTemplate.Execute(datasetPathParams(dataset, pid, username))
+}
+
+func datasetPathParams(dataset ScicatDataset, pid string, username string) destPathParams {
+	return destPathParams{
+		DatasetFolder: path.Base(dataset.SourceFolder),
+		SourceFolder:  dataset.SourceFolder,
+		Pid:           pid,
+		PidShort:      path.Base(pid),
+		PidPrefix:     path.Dir(pid),
+		PidEncoded:    url.PathEscape(pid),
+		Username:      username,
+	}
+}
+
+// builds the globus transfer of the given list of files, or of the whole source folder if no list was given
+func globusTransfer(sourceCollectionID string, sourcePath string, destCollectionID string, destPath string, fileList *[]FileToTransfer, options jobs.TransferOptions) globus.Transfer {
+	items := []globus.TransferItem{}
+	if fileList != nil {
+		// use filelist
+		for _, file := range *fileList {
+			itemType := "transfer_item"
+			if file.IsSymlink {
+				itemType = "transfer_symlink_item"
+			}
+			items = append(items, globus.TransferItem{
+				DataType:        itemType,
+				SourcePath:      sourcePath + "/" + file.Path,
+				DestinationPath: destPath + "/" + file.Path,
+			})
+		}
+	} else {
+		// sync folders through globus
+		recursive := true
+		items = append(items, globus.TransferItem{
+			DataType:        "transfer_item",
+			SourcePath:      sourcePath,
+			DestinationPath: destPath,
+			Recursive:       &recursive,
+		})
+	}
+
+	storeBasePath := false
+	transfer := globus.Transfer{
+		CommonTransfer: globus.CommonTransfer{
+			DataType:          "transfer",
+			StoreBasePathInfo: &storeBasePath,
+		},
+		SourceEndpoint:      sourceCollectionID,
+		DestinationEndpoint: destCollectionID,
+		Data:                items,
+	}
+	applyTransferOptions(&transfer, options)
+	return transfer
+}
+
+// converts the paths and file list of a transfer request to the dataset entry of its SciCat job
+func jobDataset(pid string, size int64, sourcePath string, destPath string, fileList *[]FileToTransfer) jobs.Dataset {
+	dataset := jobs.Dataset{
+		Pid:             pid,
+		Size:            size,
+		Files:           []string{},
+		SourcePath:      sourcePath,
+		DestinationPath: destPath,
+	}
+	if fileList != nil {
+		for _, file := range *fileList {
+			dataset.Files = append(dataset.Files, file.Path)
+			if file.IsSymlink {
+				dataset.Symlinks = append(dataset.Symlinks, file.Path)
+			}
+		}
+	}
+	return dataset
+}
+
+// checks that the files of a file list are within the dataset folder, as their paths are
+// appended to the source and destination paths of the dataset. An empty list is rejected, as it
+// would be stored like a transfer of the whole dataset and transfer the whole dataset on retries.
+func checkFileList(fileList *[]FileToTransfer) error {
+	if fileList == nil {
+		return nil
+	}
+	if len(*fileList) == 0 {
+		return fmt.Errorf("the file list is empty, the whole dataset is transferred if it's not set")
+	}
+	for _, file := range *fileList {
+		if file.Path == "" {
+			return fmt.Errorf("the file list contains an empty path")
+		}
+		if path.IsAbs(file.Path) {
+			return fmt.Errorf("the path '%s' isn't relative to the dataset folder", file.Path)
+		}
+		if cleaned := path.Clean(file.Path); cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+			return fmt.Errorf("the path '%s' is outside of the dataset folder", file.Path)
+		}
+	}
+	return nil
+}
+
+// converts the dataset entry of a SciCat job back to the file list of its transfer request,
+// which is nil if the whole dataset was transferred
+func datasetFileList(dataset jobs.Dataset) *[]FileToTransfer {
+	if len(dataset.Files) == 0 {
+		return nil
+	}
+	fileList := make([]FileToTransfer, len(dataset.Files))
+	for i, file := range dataset.Files {
+		fileList[i] = FileToTransfer{
+			Path:      file,
+			IsSymlink: slices.Contains(dataset.Symlinks, file),
+		}
+	}
+	return &fileList
+}
@@ -0,0 +1,345 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/SwissOpenEM/globus-transfer-service/internal/tasks"
+	"github.com/SwissOpenEM/globus-transfer-service/jobs"
+	"github.com/gin-gonic/gin"
+)
+
+// the interval of the comments sent on idle event streams, so that proxies don't close them
+const eventStreamKeepAliveInterval = 30 * time.Second
+
+const (
+	// the number of jobs whose access rights a stream of all the events keeps, and for how long, before fetching them again
+	eventStreamJobCacheSize = 1000
+	eventStreamJobCacheTTL  = 5 * time.Minute
+	// the number of jobs a stream of all the events fetches at the same time
+	eventStreamJobLookups = 4
+)
+
+// a job of an event stream as fetched for checking access rights, nil if the user can't view it
+type cachedEventJob struct {
+	job       *jobs.ScicatJob
+	fetchedAt time.Time
+}
+
+// the result of fetching the job of some events
+type eventJobLookup struct {
+	scicatJobId string
+	job         jobs.ScicatJob
+	err         error
+}
+
+// GetTransferTaskEvents streams the status changes of a single transfer job, from the queue to the end of its
+// transfers. The events are written directly to the gin context, as the generated response types can't flush the
+// events as they happen. The stream ends with the first event of a final status.
+func (s ServerHandler) GetTransferTaskEvents(ctx context.Context, req GetTransferTaskEventsRequestObject) (GetTransferTaskEventsResponseObject, error) {
+	ginCtx, ok := ctx.(*gin.Context)
+	if !ok {
+		return GetTransferTaskEvents500JSONResponse{
+			Message: getPointerOrNil("context error"),
+		}, nil
+	}
+
+	// fetch scicat user
+	u, ok := ginCtx.Get("scicatUser")
+	if !ok {
+		return GetTransferTaskEvents500JSONResponse{
+			Message: getPointerOrNil("no user was found"),
+		}, nil
+	}
+
+	scicatUser, ok := u.(User)
+	if !ok {
+		return GetTransferTaskEvents500JSONResponse{
+			Message: getPointerOrNil("invalid user in context"),
+			Details: getPointerOrNil(fmt.Sprintf("type found: '%s'", reflect.TypeOf(u))),
+		}, nil
+	}
+
+	serviceToken, err := s.scicatServiceUser.GetToken()
+	if err != nil {
+		return GetTransferTaskEvents500JSONResponse{
+			Message: getPointerOrNil("couldn't access SciCat"),
+			Details: getPointerOrNil(fmt.Sprintf("SciCat token renewal failed: %s", err.Error())),
+		}, nil
+	}
+
+	job, err := jobs.GetJobById(s.scicatUrl, serviceToken, req.ScicatJobId)
+	if err != nil {
+		return GetTransferTaskEvents400JSONResponse{
+			GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
+				Message: getPointerOrNil("failed to request job from SciCat"),
+				Details: getPointerOrNil(err.Error()),
+			},
+		}, nil
+	}
+
+	if !canViewJob(scicatUser, job) {
+		return GetTransferTaskEvents403JSONResponse{
+			Message: getPointerOrNil("you don't have the right to view this job"),
+		}, nil
+	}
+
+	// subscribe before reading the current status, so that no change is missed in between
+	events, unsubscribe := s.taskPool.SubscribeTransferEvents()
+	defer unsubscribe()
+
+	// a job leaves the queue only once it's tracked, so it's live if it's in either
+	item := s.jobToTransferItem(job)
+	queued := s.taskPool.IsQueued(job.ID)
+	_, tracked := s.taskPool.GetTransferTaskStatus(job.ID)
+	live := queued || tracked
+	if !live {
+		// the task might have ended since subscribing, in which case its last event is already buffered
+		item = s.latestBufferedTransferItem(events, job, item)
+	}
+
+	startEventStream(ginCtx)
+	writeTransferEvent(ginCtx, item)
+	if !live || isFinalTransferStatus(item.Status) {
+		return nil, nil // no further events will come for this job
+	}
+
+	keepAlive := time.NewTicker(eventStreamKeepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-ginCtx.Request.Context().Done():
+			return nil, nil
+		case <-keepAlive.C:
+			writeKeepAlive(ginCtx)
+		case event, ok := <-events:
+			if !ok {
+				return nil, nil // dropped for lagging behind, the client is expected to reconnect
+			}
+			if event.ScicatJobId != job.ID {
+				continue
+			}
+			writeTransferEvent(ginCtx, s.eventTransferItem(job, event))
+			if event.Status.Status.IsFinal() {
+				return nil, nil
+			}
+		}
+	}
+}
+
+// GetTransferEvents streams the status changes of all the transfer jobs held by the task pool that the
+// user has the right to view, queued or tracked, starting with their current status. Like GetTransferTaskEvents, it writes the
+// events directly to the gin context.
+func (s ServerHandler) GetTransferEvents(ctx context.Context, req GetTransferEventsRequestObject) (GetTransferEventsResponseObject, error) {
+	ginCtx, ok := ctx.(*gin.Context)
+	if !ok {
+		return GetTransferEvents500JSONResponse{
+			Message: getPointerOrNil("context error"),
+		}, nil
+	}
+
+	// fetch scicat user
+	u, ok := ginCtx.Get("scicatUser")
+	if !ok {
+		return GetTransferEvents500JSONResponse{
+			Message: getPointerOrNil("no user was found"),
+		}, nil
+	}
+
+	scicatUser, ok := u.(User)
+	if !ok {
+		return GetTransferEvents500JSONResponse{
+			Message: getPointerOrNil("invalid user in context"),
+			Details: getPointerOrNil(fmt.Sprintf("type found: '%s'", reflect.TypeOf(u))),
+		}, nil
+	}
+
+	if _, err := s.scicatServiceUser.GetToken(); err != nil {
+		return GetTransferEvents500JSONResponse{
+			Message: getPointerOrNil("couldn't access SciCat"),
+			Details: getPointerOrNil(fmt.Sprintf("SciCat token renewal failed: %s", err.Error())),
+		}, nil
+	}
+
+	events, unsubscribe := s.taskPool.SubscribeTransferEvents()
+	defer unsubscribe()
+
+	// jobs of the events are fetched in the background for checking access rights, so that fetching them doesn't hold
+	// back the events of the other jobs. The events of a job wait for it to be fetched, and are dropped if that fails.
+	viewableJobs := map[string]cachedEventJob{}
+	pendingEvents := map[string][]tasks.TransferEvent{}
+	lookups := make(chan eventJobLookup)
+	lookupSlots := make(chan struct{}, eventStreamJobLookups)
+	lookupCtx, cancelLookups := context.WithCancel(ginCtx.Request.Context())
+	defer cancelLookups()
+
+	writeEvent := func(job *jobs.ScicatJob, event tasks.TransferEvent) {
+		if job == nil {
+			return
+		}
+		writeTransferEvent(ginCtx, s.eventTransferItem(*job, event))
+		if event.Status.Status.IsFinal() {
+			delete(viewableJobs, event.ScicatJobId)
+		}
+	}
+	handleEvent := func(event tasks.TransferEvent) {
+		if pending, ok := pendingEvents[event.ScicatJobId]; ok {
+			pendingEvents[event.ScicatJobId] = append(pending, event)
+			return
+		}
+		if cached, ok := viewableJobs[event.ScicatJobId]; ok && time.Since(cached.fetchedAt) < eventStreamJobCacheTTL {
+			writeEvent(cached.job, event)
+			return
+		}
+		pendingEvents[event.ScicatJobId] = []tasks.TransferEvent{event}
+		go func(scicatJobId string) {
+			select {
+			case lookupSlots <- struct{}{}:
+			case <-lookupCtx.Done():
+				return
+			}
+			defer func() { <-lookupSlots }()
+
+			lookup := eventJobLookup{scicatJobId: scicatJobId}
+			serviceToken, err := s.scicatServiceUser.GetToken()
+			if err == nil {
+				lookup.job, err = jobs.GetJobById(s.scicatUrl, serviceToken, scicatJobId)
+			}
+			lookup.err = err
+			select {
+			case lookups <- lookup:
+			case <-lookupCtx.Done():
+			}
+		}(event.ScicatJobId)
+	}
+
+	startEventStream(ginCtx)
+
+	currentEvents := map[string]tasks.TransferEvent{}
+	for scicatJobId, status := range s.taskPool.GetQueuedTransferStatuses() {
+		currentEvents[scicatJobId] = tasks.TransferEvent{ScicatJobId: scicatJobId, Status: status, Queued: true}
+	}
+	for scicatJobId, status := range s.taskPool.GetTransferTaskStatuses() {
+		currentEvents[scicatJobId] = tasks.TransferEvent{ScicatJobId: scicatJobId, Status: status}
+	}
+	scicatJobIds := make([]string, 0, len(currentEvents))
+	for scicatJobId := range currentEvents {
+		scicatJobIds = append(scicatJobIds, scicatJobId)
+	}
+	slices.Sort(scicatJobIds)
+	for _, scicatJobId := range scicatJobIds {
+		handleEvent(currentEvents[scicatJobId])
+	}
+	ginCtx.Writer.Flush()
+
+	keepAlive := time.NewTicker(eventStreamKeepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-ginCtx.Request.Context().Done():
+			return nil, nil
+		case <-keepAlive.C:
+			writeKeepAlive(ginCtx)
+		case event, ok := <-events:
+			if !ok {
+				return nil, nil // dropped for lagging behind, the client is expected to reconnect
+			}
+			handleEvent(event)
+		case lookup := <-lookups:
+			pending := pendingEvents[lookup.scicatJobId]
+			delete(pendingEvents, lookup.scicatJobId)
+			if lookup.err != nil {
+				continue // not cached, the job is fetched again on its next event
+			}
+			var job *jobs.ScicatJob
+			if canViewJob(scicatUser, lookup.job) {
+				job = &lookup.job
+			}
+			cacheEventJob(viewableJobs, lookup.scicatJobId, job)
+			for _, event := range pending {
+				writeEvent(job, event)
+			}
+		}
+	}
+}
+
+// caches the job of an event stream, making room by dropping the expired jobs or else the one fetched the longest ago
+func cacheEventJob(viewableJobs map[string]cachedEventJob, scicatJobId string, job *jobs.ScicatJob) {
+	if _, ok := viewableJobs[scicatJobId]; !ok && len(viewableJobs) >= eventStreamJobCacheSize {
+		oldestJobId := ""
+		for cachedJobId, cached := range viewableJobs {
+			if time.Since(cached.fetchedAt) >= eventStreamJobCacheTTL {
+				delete(viewableJobs, cachedJobId)
+			} else if oldestJobId == "" || cached.fetchedAt.Before(viewableJobs[oldestJobId].fetchedAt) {
+				oldestJobId = cachedJobId
+			}
+		}
+		if len(viewableJobs) >= eventStreamJobCacheSize {
+			delete(viewableJobs, oldestJobId)
+		}
+	}
+	viewableJobs[scicatJobId] = cachedEventJob{job: job, fetchedAt: time.Now()}
+}
+
+func canViewJob(user User, job jobs.ScicatJob) bool {
+	return job.OwnerUser == user.Profile.Username || slices.Contains(user.Profile.AccessGroups, job.OwnerGroup)
+}
+
+func isFinalTransferStatus(status TransferStatus) bool {
+	return status == Finished || status == Failed || status == Cancelled
+}
+
+// the transfer item of an event of a job, with its position in the queue while it's queued
+func (s ServerHandler) eventTransferItem(job jobs.ScicatJob, event tasks.TransferEvent) TransferItem {
+	if !event.Queued {
+		return liveTransferItem(job, event.Status)
+	}
+	message := "waiting to be submitted to globus"
+	if event.Status.Status == jobs.Submitting {
+		message = "submitting"
+	}
+	item := transferItem(job, event.Status, message)
+	if position, priority, ok := s.taskPool.QueuePosition(job.ID); ok {
+		item.QueuePosition = &position
+		item.Priority = toTransferPriority(priority)
+	}
+	return item
+}
+
+// returns the transfer item of the last event of the job that is buffered in events, or item if there's none
+func (s ServerHandler) latestBufferedTransferItem(events <-chan tasks.TransferEvent, job jobs.ScicatJob, item TransferItem) TransferItem {
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return item
+			}
+			if event.ScicatJobId == job.ID {
+				item = s.eventTransferItem(job, event)
+			}
+		default:
+			return item
+		}
+	}
+}
+
+func startEventStream(ginCtx *gin.Context) {
+	ginCtx.Header("Content-Type", "text/event-stream")
+	ginCtx.Header("Cache-Control", "no-cache")
+	ginCtx.Header("Connection", "keep-alive")
+	ginCtx.Status(200)
+}
+
+// writes a transfer item as an event named after its status
+func writeTransferEvent(ginCtx *gin.Context, item TransferItem) {
+	ginCtx.SSEvent(string(item.Status), item)
+	ginCtx.Writer.Flush()
+}
+
+func writeKeepAlive(ginCtx *gin.Context) {
+	_, _ = ginCtx.Writer.WriteString(": keep-alive\n\n")
+	ginCtx.Writer.Flush()
+}
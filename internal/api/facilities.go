@@ -0,0 +1,68 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (s ServerHandler) GetFacilities(ctx context.Context, req GetFacilitiesRequestObject) (GetFacilitiesResponseObject, error) {
+	ginCtx, ok := ctx.(*gin.Context)
+	if !ok {
+		return GetFacilities500JSONResponse{
+			Message: getPointerOrNil("context error"),
+		}, nil
+	}
+
+	// fetch scicat user
+	u, ok := ginCtx.Get("scicatUser")
+	if !ok {
+		return GetFacilities500JSONResponse{
+			Message: getPointerOrNil("no user was found"),
+		}, nil
+	}
+
+	scicatUser, ok := u.(User)
+	if !ok {
+		return GetFacilities500JSONResponse{
+			Message: getPointerOrNil("invalid user in context"),
+			Details: getPointerOrNil(fmt.Sprintf("type found: '%s'", reflect.TypeOf(u))),
+		}, nil
+	}
+
+	facilityNames := make([]string, 0, len(s.facilityCollectionIDs))
+	for facilityName := range s.facilityCollectionIDs {
+		facilityNames = append(facilityNames, facilityName)
+	}
+	slices.Sort(facilityNames)
+
+	facilities := make([]Facility, len(facilityNames))
+	for i, facilityName := range facilityNames {
+		srcGroup, err := executeGroupTemplate(s.srcGroupTemplate, facilityName)
+		if err != nil {
+			return GetFacilities500JSONResponse{
+				Message: getPointerOrNil(fmt.Sprintf("group templating failed with facility '%s' as source", facilityName)),
+				Details: getPointerOrNil(err.Error()),
+			}, nil
+		}
+
+		dstGroup, err := executeGroupTemplate(s.dstGroupTemplate, facilityName)
+		if err != nil {
+			return GetFacilities500JSONResponse{
+				Message: getPointerOrNil(fmt.Sprintf("group templating failed with facility '%s' as destination", facilityName)),
+				Details: getPointerOrNil(err.Error()),
+			}, nil
+		}
+
+		facilities[i] = Facility{
+			Name:             facilityName,
+			CanBeSource:      slices.Contains(scicatUser.Profile.AccessGroups, srcGroup),
+			CanBeDestination: slices.Contains(scicatUser.Profile.AccessGroups, dstGroup),
+		}
+	}
+
+	return GetFacilities200JSONResponse(facilities), nil
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// the state of a dependency of the service, as reported by the readiness probe
+type readinessCheck struct {
+	Ready bool   `json:"ready"`
+	Error string `json:"error,omitempty"`
+}
+
+type readinessResponse struct {
+	Ready  bool                      `json:"ready"`
+	Checks map[string]readinessCheck `json:"checks"`
+}
+
+// reports that the process is alive, without checking any of its dependencies
+func healthzHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// reports whether the service can handle transfer requests, which requires the SciCat service user to be
+// logged in, Globus to be reachable with the client's credentials, and the task pool to accept tasks
+func readyzHandler(api *ServerHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		resp := readinessResponse{
+			Ready: true,
+			Checks: map[string]readinessCheck{
+				"scicat":   api.checkScicatReadiness(),
+				"globus":   api.checkGlobusReadiness(),
+				"taskPool": api.checkTaskPoolReadiness(),
+			},
+		}
+		for _, check := range resp.Checks {
+			resp.Ready = resp.Ready && check.Ready
+		}
+
+		status := http.StatusOK
+		if !resp.Ready {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, resp)
+	}
+}
+
+func (s ServerHandler) checkScicatReadiness() readinessCheck {
+	if _, err := s.scicatServiceUser.GetToken(); err != nil {
+		return readinessCheck{Error: fmt.Sprintf("can't get the token of the service user: %s", err.Error())}
+	}
+	return readinessCheck{Ready: true}
+}
+
+func (s ServerHandler) checkGlobusReadiness() readinessCheck {
+	if !s.globusClient.IsClientSet() {
+		return readinessCheck{Error: "the globus client is not set up"}
+	}
+	// listing a single task requires both a valid token and access to the transfer api
+	if _, err := s.globusClient.TransferGetTaskList(0, 1); err != nil {
+		return readinessCheck{Error: fmt.Sprintf("can't reach the globus transfer api: %s", err.Error())}
+	}
+	return readinessCheck{Ready: true}
+}
+
+func (s ServerHandler) checkTaskPoolReadiness() readinessCheck {
+	if !s.taskPool.IsAcceptingTasks() {
+		return readinessCheck{Error: "the task pool is stopped, shutting down or already tracking as many transfers as it can"}
+	}
+	return readinessCheck{Ready: true}
+}
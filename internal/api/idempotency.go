@@ -0,0 +1,136 @@
+package api
+
+import (
+	"encoding/json"
+	"slices"
+	"sync"
+
+	"github.com/SwissOpenEM/globus-transfer-service/jobs"
+)
+
+// serializes the submissions of transfers that share a key, so that looking for an existing job
+// and creating a new one can't interleave between concurrent requests
+type submissionLocks struct {
+	mutex *sync.Mutex
+	locks map[string]*submissionLock
+}
+
+type submissionLock struct {
+	mutex   sync.Mutex
+	holders int
+}
+
+func newSubmissionLocks() *submissionLocks {
+	return &submissionLocks{
+		mutex: &sync.Mutex{},
+		locks: map[string]*submissionLock{},
+	}
+}
+
+// locks the key and returns the function that unlocks it
+func (l *submissionLocks) lock(key string) func() {
+	l.mutex.Lock()
+	keyLock, ok := l.locks[key]
+	if !ok {
+		keyLock = &submissionLock{}
+		l.locks[key] = keyLock
+	}
+	keyLock.holders++
+	l.mutex.Unlock()
+
+	keyLock.mutex.Lock()
+	return func() {
+		keyLock.mutex.Unlock()
+		l.mutex.Lock()
+		keyLock.holders--
+		if keyLock.holders == 0 {
+			delete(l.locks, key)
+		}
+		l.mutex.Unlock()
+	}
+}
+
+// locks the submission of the transfers of the datasets to the facility and the idempotency key of the user, if any,
+// so that concurrent requests for the same datasets or with the same key are handled one at a time, and returns the
+// function that unlocks them. The keys are always locked in the same order, so that requests sharing several of them
+// can't deadlock.
+func (s ServerHandler) lockSubmission(username string, idempotencyKey string, pids []string, destFacility string) func() {
+	keys := make([]string, len(pids))
+	for i, pid := range pids {
+		keys[i] = pid + "|" + destFacility
+	}
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+	if idempotencyKey != "" {
+		keys = append([]string{"idempotency:" + username + "|" + idempotencyKey}, keys...)
+	}
+
+	unlocks := make([]func(), len(keys))
+	for i, key := range keys {
+		unlocks[i] = s.submissionLocks.lock(key)
+	}
+	return func() {
+		for i := len(unlocks) - 1; i >= 0; i-- {
+			unlocks[i]()
+		}
+	}
+}
+
+// finds the transfer job created by an earlier request of the user with the same idempotency key
+func (s ServerHandler) findIdempotentTransfer(serviceToken string, username string, idempotencyKey string) (jobs.ScicatJob, bool, error) {
+	return s.findTransfer(serviceToken, []map[string]any{
+		{"type": jobs.GlobusTransferJobType},
+		{"ownerUser": username},
+		{"jobParams.idempotencyKey": idempotencyKey},
+	}, func(jobs.ScicatJob) bool { return true })
+}
+
+// finds a transfer job of the dataset to the facility that hasn't ended yet. Only the jobs queued or tracked by the
+// task pool are considered, as the others can't progress anymore even if their status in SciCat was never updated.
+func (s ServerHandler) findOngoingTransfer(serviceToken string, pid string, destFacility string) (jobs.ScicatJob, bool, error) {
+	return s.findTransfer(serviceToken, []map[string]any{
+		{"type": jobs.GlobusTransferJobType},
+		{"jobParams.datasetList.pid": pid},
+		{"jobParams.destinationFacility": destFacility},
+		{"jobResultObject.status": map[string]any{"$in": []jobs.JobStatus{jobs.Waiting, jobs.Submitting, jobs.Transferring, jobs.Stalled}}},
+	}, func(job jobs.ScicatJob) bool {
+		_, tracked := s.taskPool.GetTransferTaskStatus(job.ID)
+		return tracked || s.taskPool.IsQueued(job.ID)
+	})
+}
+
+// returns the most recent job matching all the conditions that is accepted by the filter function
+func (s ServerHandler) findTransfer(serviceToken string, conditions []map[string]any, accept func(jobs.ScicatJob) bool) (jobs.ScicatJob, bool, error) {
+	filter, err := json.Marshal(transferListFilter{
+		Where: map[string]any{"$and": conditions},
+		Limits: transferListLimits{
+			Limit: maxTransferListLimit,
+			Order: "createdAt:desc",
+		},
+	})
+	if err != nil {
+		return jobs.ScicatJob{}, false, err
+	}
+
+	jobList, err := jobs.GetJobList(s.scicatUrl, serviceToken, string(filter))
+	if err != nil {
+		return jobs.ScicatJob{}, false, err
+	}
+
+	for _, job := range jobList {
+		if accept(job) {
+			return job, true, nil
+		}
+	}
+	return jobs.ScicatJob{}, false, nil
+}
+
+// whether the job transfers the given datasets, in the same order, between the given facilities
+func isSameTransfer(job jobs.ScicatJob, pids []string, sourceFacility string, destFacility string) bool {
+	if job.JobParams.SourceFacility != sourceFacility || job.JobParams.DestinationFacility != destFacility {
+		return false
+	}
+	return slices.EqualFunc(job.JobParams.DatasetList, pids, func(dataset jobs.Dataset, pid string) bool {
+		return dataset.Pid == pid
+	})
+}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/SwissOpenEM/globus-transfer-service/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// the facility label of transfer requests with a facility that isn't configured, which keeps the number of
+// label values bounded regardless of what is requested
+const unknownFacilityLabel = "unknown"
+
+// TransferRequestMetricsMiddleware counts the requests for single and batch transfers by response status and
+// facility pair, including the ones rejected by the authentication middleware
+func TransferRequestMetricsMiddleware(m *metrics.Metrics, facilityCollectionIDs map[string]string) gin.HandlerFunc {
+	facilityLabel := func(facility string) string {
+		if _, ok := facilityCollectionIDs[facility]; !ok {
+			return unknownFacilityLabel
+		}
+		return facility
+	}
+
+	return func(c *gin.Context) {
+		c.Next()
+		if c.Request.Method != http.MethodPost || (c.FullPath() != "/transfer" && c.FullPath() != "/transfer/batch") {
+			return
+		}
+		m.ObserveTransferRequest(c.Writer.Status(), facilityLabel(c.Query("sourceFacility")), facilityLabel(c.Query("destFacility")))
+	}
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"fmt"
+	"slices"
+	"text/template"
+
+	"github.com/SwissOpenEM/globus-transfer-service/jobs"
+)
+
+type PriorityNotAllowedError struct {
+	msg string
+}
+
+func (e *PriorityNotAllowedError) Error() string {
+	return e.msg
+}
+
+type UnknownPriorityError struct {
+	msg string
+}
+
+func (e *UnknownPriorityError) Error() string {
+	return e.msg
+}
+
+// parses the templates of the groups allowed to request each priority, which must be known priorities
+func parsePriorityGroupTemplates(groupTemplateBodies map[string]string) (map[jobs.Priority]*template.Template, error) {
+	groupTemplates := map[jobs.Priority]*template.Template{}
+	for priority, body := range groupTemplateBodies {
+		if priority == "" || !jobs.Priority(priority).IsValid() {
+			return nil, fmt.Errorf("unknown priority '%s' in the priority group templates", priority)
+		}
+		groupTemplate, err := template.New(priority + " priority group template").Parse(body)
+		if err != nil {
+			return nil, err
+		}
+		groupTemplates[jobs.Priority(priority)] = groupTemplate
+	}
+	return groupTemplates, nil
+}
+
+// resolves the priority requested for a transfer between two facilities, which can be nil. Returns an
+// UnknownPriorityError if the priority doesn't exist, and a PriorityNotAllowedError if it's restricted and the
+// user isn't in its group for either facility.
+func (s ServerHandler) transferPriority(user User, sourceFacility string, destFacility string, requested *TransferPriority) (jobs.Priority, error) {
+	if requested == nil {
+		return jobs.PriorityNormal, nil
+	}
+	priority := jobs.Priority(*requested)
+	if priority == "" || !priority.IsValid() {
+		return "", &UnknownPriorityError{fmt.Sprintf("unknown priority '%s'", priority)}
+	}
+
+	groupTemplate, restricted := s.priorityGroupTemplates[priority]
+	if !restricted {
+		return priority, nil
+	}
+	allowedGroups := []string{}
+	for _, facility := range []string{sourceFacility, destFacility} {
+		group, err := executeGroupTemplate(groupTemplate, facility)
+		if err != nil {
+			return "", err
+		}
+		if slices.Contains(user.Profile.AccessGroups, group) {
+			return priority, nil
+		}
+		allowedGroups = append(allowedGroups, group)
+	}
+	return "", &PriorityNotAllowedError{fmt.Sprintf("the '%s' priority is restricted to the groups '%v'", priority, slices.Compact(allowedGroups))}
+}
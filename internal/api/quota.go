@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (s ServerHandler) GetQuota(ctx context.Context, req GetQuotaRequestObject) (GetQuotaResponseObject, error) {
+	ginCtx, ok := ctx.(*gin.Context)
+	if !ok {
+		return GetQuota500JSONResponse{
+			Message: getPointerOrNil("context error"),
+		}, nil
+	}
+
+	// fetch scicat user
+	u, ok := ginCtx.Get("scicatUser")
+	if !ok {
+		return GetQuota500JSONResponse{
+			Message: getPointerOrNil("no user was found"),
+		}, nil
+	}
+
+	scicatUser, ok := u.(User)
+	if !ok {
+		return GetQuota500JSONResponse{
+			Message: getPointerOrNil("invalid user in context"),
+			Details: getPointerOrNil(fmt.Sprintf("type found: '%s'", reflect.TypeOf(u))),
+		}, nil
+	}
+
+	groups := slices.Clone(scicatUser.Profile.AccessGroups)
+	slices.Sort(groups)
+	groups = slices.Compact(groups)
+	usages, err := s.taskPool.GroupUsages(groups, time.Now())
+	if err != nil {
+		return GetQuota500JSONResponse{
+			Message: getPointerOrNil("failed to count the usage of the quotas"),
+			Details: getPointerOrNil(err.Error()),
+		}, nil
+	}
+
+	groupQuotas := make([]GroupQuota, len(groups))
+	for i, group := range groups {
+		usage := usages[group]
+		groupQuotas[i] = GroupQuota{
+			Group:              group,
+			QueuedTransfers:    usage.QueuedTransfers,
+			MaxQueuedTransfers: getPointerOrNil(int(s.quotas.MaxQueuedTransfersPerGroup)),
+			DailyBytes:         usage.DailyBytes,
+			MaxDailyBytes:      getPointerOrNil(int64(s.quotas.MaxDailyBytesPerGroup)),
+			MonthlyBytes:       usage.MonthlyBytes,
+			MaxMonthlyBytes:    getPointerOrNil(int64(s.quotas.MaxMonthlyBytesPerGroup)),
+		}
+	}
+
+	return GetQuota200JSONResponse{
+		User: UserQuota{
+			ActiveTransfers:    s.taskPool.ActiveTransfers(scicatUser.Profile.Username),
+			MaxActiveTransfers: getPointerOrNil(int(s.quotas.MaxActiveTransfersPerUser)),
+		},
+		Groups: groupQuotas,
+	}, nil
+}
+
+// whether any quota is configured, in which case transfer requests are handled one at a time
+func (s ServerHandler) hasQuotas() bool {
+	return s.quotas.MaxActiveTransfersPerUser > 0 || s.quotas.MaxQueuedTransfersPerGroup > 0 ||
+		s.quotas.MaxDailyBytesPerGroup > 0 || s.quotas.MaxMonthlyBytesPerGroup > 0
+}
+
+// returns the first quota that a request for one transfer of the given size would exceed, or nil if it's within
+// all of them. The user quotas apply to the owner user of the transfer, the group quotas to its owner group.
+func (s ServerHandler) exceededQuota(ownerUser string, ownerGroup string, size int64) (*QuotaExceeded, error) {
+	if s.quotas.MaxActiveTransfersPerUser > 0 {
+		active := s.taskPool.ActiveTransfers(ownerUser)
+		if exceeded := quotaExceeded(ActiveTransfers, ownerUser, "", uint64(s.quotas.MaxActiveTransfersPerUser), int64(active), 1); exceeded != nil {
+			return exceeded, nil
+		}
+	}
+	if s.quotas.MaxQueuedTransfersPerGroup == 0 && s.quotas.MaxDailyBytesPerGroup == 0 && s.quotas.MaxMonthlyBytesPerGroup == 0 {
+		return nil, nil
+	}
+
+	usages, err := s.taskPool.GroupUsages([]string{ownerGroup}, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	usage := usages[ownerGroup]
+	if exceeded := quotaExceeded(QueuedTransfers, "", ownerGroup, uint64(s.quotas.MaxQueuedTransfersPerGroup), int64(usage.QueuedTransfers), 1); exceeded != nil {
+		return exceeded, nil
+	}
+	if exceeded := quotaExceeded(DailyBytes, "", ownerGroup, s.quotas.MaxDailyBytesPerGroup, usage.DailyBytes, size); exceeded != nil {
+		return exceeded, nil
+	}
+	return quotaExceeded(MonthlyBytes, "", ownerGroup, s.quotas.MaxMonthlyBytesPerGroup, usage.MonthlyBytes, size), nil
+}
+
+// the details of a quota of a user or a group if the requested amount doesn't fit within it, nil otherwise or if the
+// quota is unlimited
+func quotaExceeded(quota QuotaExceededQuota, user string, group string, limit uint64, used int64, requested int64) *QuotaExceeded {
+	if limit == 0 || used+requested <= int64(limit) {
+		return nil
+	}
+	message := fmt.Sprintf("the request would exceed the '%s' quota of the user '%s'", quota, user)
+	if group != "" {
+		message = fmt.Sprintf("the request would exceed the '%s' quota of the group '%s'", quota, group)
+	}
+	return &QuotaExceeded{
+		Message:   message,
+		Quota:     quota,
+		Group:     getPointerOrNil(group),
+		Limit:     int64(limit),
+		Used:      used,
+		Requested: requested,
+		Remaining: max(int64(limit)-used, 0),
+	}
+}
@@ -0,0 +1,252 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+
+	"github.com/SwissOpenEM/globus"
+	"github.com/SwissOpenEM/globus-transfer-service/internal/tasks"
+	"github.com/SwissOpenEM/globus-transfer-service/jobs"
+	"github.com/gin-gonic/gin"
+)
+
+// the number of the most recent transfers of a dataset to a facility that are searched for a finished one
+const retrievalSourceSearchLimit = 20
+
+// PostRetrievalTask transfers a dataset back from a facility it was previously transferred to by the service
+func (s ServerHandler) PostRetrievalTask(ctx context.Context, request PostRetrievalTaskRequestObject) (PostRetrievalTaskResponseObject, error) {
+	ginCtx, ok := ctx.(*gin.Context)
+	if !ok {
+		return PostRetrievalTask500JSONResponse{
+			Message: getPointerOrNil("context error"),
+		}, nil
+	}
+
+	// check facility id's and fetch collection id's
+	sourceCollectionID, ok := s.facilityCollectionIDs[request.Params.SourceFacility]
+	if !ok {
+		return PostRetrievalTask403JSONResponse{
+			Message: getPointerOrNil("invalid source facility"),
+		}, nil
+	}
+	destCollectionID, ok := s.facilityCollectionIDs[request.Params.DestFacility]
+	if !ok {
+		return PostRetrievalTask403JSONResponse{
+			Message: getPointerOrNil("invalid destination facility"),
+		}, nil
+	}
+
+	u, ok := ginCtx.Get("scicatUser")
+	if !ok {
+		return PostRetrievalTask500JSONResponse{
+			Message: getPointerOrNil("no user was found"),
+		}, nil
+	}
+
+	// fetch scicat user
+	scicatUser, ok := u.(User)
+	if !ok {
+		return PostRetrievalTask500JSONResponse{
+			Message: getPointerOrNil("invalid user in context"),
+			Details: getPointerOrNil(fmt.Sprintf("type found: '%s'", reflect.TypeOf(u))),
+		}, nil
+	}
+
+	// fetch related dataset
+	dataset, err := fetchScicatDataset(s.scicatUrl, scicatUser.ScicatToken, request.Params.ScicatPid)
+	if err != nil {
+		notAccessibleErr := &DatasetNotAccessibleError{}
+		if errors.As(err, &notAccessibleErr) {
+			return PostRetrievalTask400JSONResponse{
+				GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
+					Message: getPointerOrNil("the dataset with the given pid does not exist or you don't have access rights to it"),
+					Details: getPointerOrNil(notAccessibleErr.Error()),
+				},
+			}, nil
+		}
+		return PostRetrievalTask500JSONResponse{
+			Message: getPointerOrNil("failed to fetch the dataset"),
+			Details: getPointerOrNil(err.Error()),
+		}, nil
+	}
+
+	// check for required group memberships, the facility the dataset is retrieved from doesn't need any
+	dstGroup, err := executeGroupTemplate(s.dstGroupTemplate, request.Params.DestFacility)
+	if err != nil {
+		return PostRetrievalTask500JSONResponse{
+			Message: getPointerOrNil("group templating failed with destination facility"),
+			Details: getPointerOrNil(err.Error()),
+		}, nil
+	}
+
+	missingGroups := missingGroups(scicatUser, dstGroup, dataset.OwnerGroup)
+	if len(missingGroups) > 0 {
+		return PostRetrievalTask401JSONResponse{
+			Message: getPointerOrNil("you don't have the required access groups to request this retrieval"),
+			Details: getPointerOrNil(fmt.Sprintf("missing groups: '%v'", missingGroups)),
+		}, nil
+	}
+
+	serviceUserToken, err := s.scicatServiceUser.GetToken()
+	if err != nil {
+		return PostRetrievalTask500JSONResponse{
+			Message: getPointerOrNil("service user login failed"),
+			Details: getPointerOrNil(err.Error()),
+		}, nil
+	}
+
+	sourcePath, found, err := s.transferredDatasetPath(serviceUserToken, dataset, request.Params.ScicatPid, request.Params.SourceFacility)
+	if err != nil {
+		return PostRetrievalTask500JSONResponse{
+			Message: getPointerOrNil("couldn't find where the dataset was transferred to"),
+			Details: getPointerOrNil(err.Error()),
+		}, nil
+	}
+	if !found {
+		return PostRetrievalTask400JSONResponse{
+			GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
+				Message: getPointerOrNil("the dataset was never successfully transferred to the source facility"),
+			},
+		}, nil
+	}
+
+	// request the transfer
+	unlock := s.lockSubmission(scicatUser.Profile.Username, "", []string{request.Params.ScicatPid}, request.Params.DestFacility)
+	defer unlock()
+	failed, unlockQueue := s.checkSubmission(serviceUserToken, submissionCheck{
+		pids:         []string{request.Params.ScicatPid},
+		destFacility: request.Params.DestFacility,
+		ownerUser:    scicatUser.Profile.Username,
+		ownerGroup:   dataset.OwnerGroup,
+		size:         dataset.Size,
+	}, false)
+	defer unlockQueue()
+	if len(failed) > 0 {
+		return postRetrievalTaskFailure(failed[0]), nil
+	}
+
+	destPath, err := s.datasetRetrievalPath(dataset, request.Params.ScicatPid, scicatUser.Profile.Username)
+	if err != nil {
+		return PostRetrievalTask500JSONResponse{
+			Message: getPointerOrNil("couldn't template destination folder for the retrieval"),
+			Details: getPointerOrNil(err.Error()),
+		}, nil
+	}
+
+	options, err := s.transferOptions(request.Params.SourceFacility, request.Params.DestFacility, nil)
+	if err != nil {
+		return PostRetrievalTask500JSONResponse{
+			Message: getPointerOrNil("the configured transfer options of the facilities are not allowed"),
+			Details: getPointerOrNil(err.Error()),
+		}, nil
+	}
+
+	scicatJob, err := s.taskPool.SubmitTransfer(scicatUser.Profile.Username, dataset.OwnerGroup, jobs.JobParams{
+		DatasetList:         []jobs.Dataset{jobDataset(request.Params.ScicatPid, dataset.Size, sourcePath, destPath, nil)},
+		SourceFacility:      request.Params.SourceFacility,
+		DestinationFacility: request.Params.DestFacility,
+		Retrieval:           true,
+		TransferOptions:     &options,
+	}, []globus.Transfer{
+		globusTransfer(sourceCollectionID, sourcePath, destCollectionID, destPath, nil, options),
+	})
+	if err != nil {
+		return PostRetrievalTask500JSONResponse{
+			Message: getPointerOrNil(submissionFailure(err, "failed creating retrieval job in SciCat")),
+			Details: getPointerOrNil(err.Error()),
+		}, nil
+	}
+
+	// return response
+	return PostRetrievalTask200JSONResponse{
+		JobId: scicatJob.ID,
+	}, nil
+}
+
+// the response of PostRetrievalTask to a failed check of the submission of its transfer
+func postRetrievalTaskFailure(failed failedTransferCheck) PostRetrievalTaskResponseObject {
+	switch failed.status {
+	case http.StatusConflict:
+		return PostRetrievalTask409JSONResponse{
+			Message: getPointerOrNil(failed.message),
+			Details: getPointerOrNil(failed.details),
+		}
+	case http.StatusTooManyRequests:
+		return PostRetrievalTask429JSONResponse(*failed.quota)
+	case http.StatusServiceUnavailable:
+		return PostRetrievalTask503JSONResponse{
+			Message: getPointerOrNil(failed.message),
+			Details: getPointerOrNil(failed.details),
+		}
+	default:
+		return PostRetrievalTask500JSONResponse{
+			Message: getPointerOrNil(failed.message),
+			Details: getPointerOrNil(failed.details),
+		}
+	}
+}
+
+// finds where the service put the dataset in the collection of the facility, using the most recent transfer
+// of the dataset to that facility that finished. Returns false if the dataset was never transferred there.
+// Jobs created before their facilities were stored are matched by the destination collection of their Globus task.
+func (s ServerHandler) transferredDatasetPath(serviceToken string, dataset ScicatDataset, pid string, facility string) (string, bool, error) {
+	filter, err := json.Marshal(transferListFilter{
+		Where: map[string]any{"$and": []map[string]any{
+			{"type": jobs.GlobusTransferJobType},
+			{"jobParams.datasetList.pid": pid},
+			{"$or": []map[string]any{
+				{"jobParams.destinationFacility": facility},
+				{"jobParams.destinationFacility": map[string]any{"$exists": false}},
+			}},
+			{"jobParams.retrieval": map[string]any{"$ne": true}},
+		}},
+		Limits: transferListLimits{
+			Limit: retrievalSourceSearchLimit,
+			Order: "createdAt:desc",
+		},
+	})
+	if err != nil {
+		return "", false, err
+	}
+
+	jobList, err := jobs.GetJobList(s.scicatUrl, serviceToken, string(filter))
+	if err != nil {
+		return "", false, err
+	}
+
+	for _, job := range jobList {
+		datasetTransfers, err := tasks.JobDatasetTransfers(job)
+		if err != nil {
+			continue
+		}
+		transferIndex := slices.IndexFunc(datasetTransfers, func(d jobs.DatasetTransfer) bool { return d.Pid == pid && d.Status == jobs.Finished })
+		if transferIndex < 0 {
+			continue
+		}
+		if job.JobParams.DestinationFacility == "" {
+			task, err := s.globusClient.TransferGetTaskByID(datasetTransfers[transferIndex].GlobusTaskId)
+			if err != nil {
+				return "", false, fmt.Errorf("failed to fetch the globus task of job '%s': %w", job.ID, err)
+			}
+			if task.DestinationEndpointId == nil || *task.DestinationEndpointId != s.facilityCollectionIDs[facility] {
+				continue
+			}
+		}
+
+		if datasetIndex := slices.IndexFunc(job.JobParams.DatasetList, func(d jobs.Dataset) bool { return d.Pid == pid }); datasetIndex >= 0 {
+			if destPath := job.JobParams.DatasetList[datasetIndex].DestinationPath; destPath != "" {
+				return destPath, true, nil
+			}
+		}
+
+		// jobs created before their paths were stored put the dataset at its templated destination
+		destPath, err := s.datasetDestinationPath(dataset, pid, job.OwnerUser)
+		return destPath, err == nil, err
+	}
+	return "", false, nil
+}
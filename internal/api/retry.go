@@ -0,0 +1,277 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/SwissOpenEM/globus"
+	"github.com/SwissOpenEM/globus-transfer-service/internal/tasks"
+	"github.com/SwissOpenEM/globus-transfer-service/jobs"
+	"github.com/gin-gonic/gin"
+)
+
+// the minimum globus sync level of retried transfers, which only copies files whose size differs or whose
+// source was modified after the destination, so that the files copied by previous attempts are skipped
+const retrySyncLevel = Mtime
+
+// RetryTransferTask transfers the failed or cancelled datasets of a transfer job again, as part of the same SciCat job
+func (s ServerHandler) RetryTransferTask(ctx context.Context, req RetryTransferTaskRequestObject) (RetryTransferTaskResponseObject, error) {
+	ginCtx, ok := ctx.(*gin.Context)
+	if !ok {
+		return RetryTransferTask500JSONResponse{
+			Message: getPointerOrNil("context error"),
+		}, nil
+	}
+
+	// fetch scicat user
+	u, ok := ginCtx.Get("scicatUser")
+	if !ok {
+		return RetryTransferTask500JSONResponse{
+			Message: getPointerOrNil("no user was found"),
+		}, nil
+	}
+
+	scicatUser, ok := u.(User)
+	if !ok {
+		return RetryTransferTask500JSONResponse{
+			Message: getPointerOrNil("invalid user in context"),
+			Details: getPointerOrNil(fmt.Sprintf("type found: '%s'", reflect.TypeOf(u))),
+		}, nil
+	}
+
+	// the job is fetched and checked again by concurrent retries only once the previous one was queued
+	unlock := s.submissionLocks.lock(req.ScicatJobId)
+	defer unlock()
+
+	serviceToken, err := s.scicatServiceUser.GetToken()
+	if err != nil {
+		return RetryTransferTask500JSONResponse{
+			Message: getPointerOrNil("couldn't access SciCat"),
+			Details: getPointerOrNil(fmt.Sprintf("SciCat token renewal failed: %s", err.Error())),
+		}, nil
+	}
+
+	job, err := jobs.GetJobById(s.scicatUrl, serviceToken, req.ScicatJobId)
+	if err != nil {
+		return RetryTransferTask400JSONResponse{
+			GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
+				Message: getPointerOrNil("failed to request job from SciCat"),
+				Details: getPointerOrNil(err.Error()),
+			},
+		}, nil
+	}
+
+	if !canViewJob(scicatUser, job) {
+		return RetryTransferTask403JSONResponse{
+			Message: getPointerOrNil("you don't have the right to retry this job"),
+		}, nil
+	}
+
+	if job.Type != jobs.GlobusTransferJobType {
+		return RetryTransferTask400JSONResponse{
+			GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
+				Message: getPointerOrNil("the job is not a transfer job"),
+			},
+		}, nil
+	}
+
+	if _, ok := s.taskPool.GetTransferTaskStatus(job.ID); ok || s.taskPool.IsQueued(job.ID) {
+		return RetryTransferTask400JSONResponse{
+			GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
+				Message: getPointerOrNil("the transfer is still ongoing"),
+			},
+		}, nil
+	}
+
+	datasetTransfers, err := tasks.JobDatasetTransfers(job)
+	if err != nil {
+		return RetryTransferTask400JSONResponse{
+			GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
+				Message: getPointerOrNil("the job can't be retried"),
+				Details: getPointerOrNil(err.Error()),
+			},
+		}, nil
+	}
+
+	retried := []int{}
+	for i, datasetTransfer := range datasetTransfers {
+		if datasetTransfer.Status == jobs.Failed || datasetTransfer.Status == jobs.Cancelled {
+			retried = append(retried, i)
+		}
+	}
+	if len(retried) == 0 {
+		return RetryTransferTask400JSONResponse{
+			GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
+				Message: getPointerOrNil("only failed or cancelled transfers can be retried"),
+			},
+		}, nil
+	}
+
+	// the job has to hold the facilities to transfer between again
+	sourceCollectionID, ok := s.facilityCollectionIDs[job.JobParams.SourceFacility]
+	if !ok {
+		return RetryTransferTask400JSONResponse{
+			GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
+				Message: getPointerOrNil("the source facility of the job is unknown, it can't be retried"),
+				Details: getPointerOrNil(fmt.Sprintf("source facility: '%s'", job.JobParams.SourceFacility)),
+			},
+		}, nil
+	}
+	destCollectionID, ok := s.facilityCollectionIDs[job.JobParams.DestinationFacility]
+	if !ok {
+		return RetryTransferTask400JSONResponse{
+			GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
+				Message: getPointerOrNil("the destination facility of the job is unknown, it can't be retried"),
+				Details: getPointerOrNil(fmt.Sprintf("destination facility: '%s'", job.JobParams.DestinationFacility)),
+			},
+		}, nil
+	}
+
+	// check for required group memberships, which are the same as for requesting the transfer
+	srcGroup, err := executeGroupTemplate(s.srcGroupTemplate, job.JobParams.SourceFacility)
+	if err != nil {
+		return RetryTransferTask500JSONResponse{
+			Message: getPointerOrNil("group templating failed with source facility"),
+			Details: getPointerOrNil(err.Error()),
+		}, nil
+	}
+
+	dstGroup, err := executeGroupTemplate(s.dstGroupTemplate, job.JobParams.DestinationFacility)
+	if err != nil {
+		return RetryTransferTask500JSONResponse{
+			Message: getPointerOrNil("group templating failed with destination facility"),
+			Details: getPointerOrNil(err.Error()),
+		}, nil
+	}
+
+	requiredGroups := []string{dstGroup, job.OwnerGroup}
+	if !job.JobParams.Retrieval {
+		requiredGroups = append(requiredGroups, srcGroup) // retrievals don't require rights on the facility they retrieve from
+	}
+	missingGroups := missingGroups(scicatUser, requiredGroups...)
+	if len(missingGroups) > 0 {
+		return RetryTransferTask401JSONResponse{
+			Message: getPointerOrNil("you don't have the required access groups to retry this transfer"),
+			Details: getPointerOrNil(fmt.Sprintf("missing groups: '%v'", missingGroups)),
+		}, nil
+	}
+
+	// retries keep the options of the job, unless its sync level would copy files again
+	options := jobs.TransferOptions{}
+	if job.JobParams.TransferOptions != nil {
+		options = *job.JobParams.TransferOptions
+	}
+	if syncLevel, ok := syncLevels[options.SyncLevel]; !ok || syncLevel < syncLevels[string(retrySyncLevel)] {
+		options.SyncLevel = string(retrySyncLevel)
+	}
+
+	// a deadline that passed is renewed with the maximum duration allowed between the facilities, from when the
+	// retry leaves the queue
+	options.Deadline = job.JobResultObject.Deadline
+	if options.Deadline != nil && !options.Deadline.After(time.Now()) {
+		options.Deadline = nil
+		options.MaxDuration = s.transferOptionRule(job.JobParams.SourceFacility, job.JobParams.DestinationFacility).MaxDuration
+	}
+
+	transfers := make([]globus.Transfer, len(retried))
+	retriedPids := make([]string, len(retried))
+	retriedSize := int64(0)
+	for j, i := range retried {
+		pid := datasetTransfers[i].Pid
+		retriedPids[j] = pid
+		datasetEntry := jobs.Dataset{Pid: pid}
+		if datasetIndex := slices.IndexFunc(job.JobParams.DatasetList, func(d jobs.Dataset) bool { return d.Pid == pid }); datasetIndex >= 0 {
+			datasetEntry = job.JobParams.DatasetList[datasetIndex]
+		}
+		retriedSize += datasetEntry.Size
+
+		// jobs created before their paths were stored are transfers from the dataset's source folder
+		sourcePath, destPath := datasetEntry.SourcePath, datasetEntry.DestinationPath
+		if sourcePath == "" || destPath == "" {
+			dataset, err := fetchScicatDataset(s.scicatUrl, scicatUser.ScicatToken, pid)
+			if err != nil {
+				notAccessibleErr := &DatasetNotAccessibleError{}
+				if errors.As(err, &notAccessibleErr) {
+					return RetryTransferTask400JSONResponse{
+						GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
+							Message: getPointerOrNil(fmt.Sprintf("the dataset '%s' does not exist or you don't have access rights to it", pid)),
+							Details: getPointerOrNil(notAccessibleErr.Error()),
+						},
+					}, nil
+				}
+				return RetryTransferTask500JSONResponse{
+					Message: getPointerOrNil(fmt.Sprintf("failed to fetch the dataset '%s'", pid)),
+					Details: getPointerOrNil(err.Error()),
+				}, nil
+			}
+
+			// the destination is templated with the user that requested the transfer in the first place
+			sourcePath = dataset.SourceFolder
+			destPath, err = s.datasetDestinationPath(dataset, pid, job.OwnerUser)
+			if err != nil {
+				return RetryTransferTask500JSONResponse{
+					Message: getPointerOrNil(fmt.Sprintf("couldn't template destination folder for the transfer of '%s'", pid)),
+					Details: getPointerOrNil(err.Error()),
+				}, nil
+			}
+		}
+
+		transfers[j] = globusTransfer(sourceCollectionID, sourcePath, destCollectionID, destPath, datasetFileList(datasetEntry), options)
+	}
+
+	// request the transfers, which count towards the quotas of the owners of the job
+	unlockDatasets := s.lockSubmission(job.OwnerUser, "", retriedPids, job.JobParams.DestinationFacility)
+	defer unlockDatasets()
+	failed, unlockQueue := s.checkSubmission(serviceToken, submissionCheck{
+		pids:         retriedPids,
+		destFacility: job.JobParams.DestinationFacility,
+		ownerUser:    job.OwnerUser,
+		ownerGroup:   job.OwnerGroup,
+		size:         retriedSize,
+	}, false)
+	defer unlockQueue()
+	if len(failed) > 0 {
+		return retryTransferTaskFailure(failed[0]), nil
+	}
+
+	// the retried transfers wait in the queue like new ones
+	jobParams := job.JobParams
+	jobParams.TransferOptions = &options
+	job, err = s.taskPool.RetryTransfer(job, datasetTransfers, retried, jobParams, transfers)
+	if err != nil {
+		return RetryTransferTask500JSONResponse{
+			Message: getPointerOrNil(submissionFailure(err, "failed updating transfer job in SciCat")),
+			Details: getPointerOrNil(err.Error()),
+		}, nil
+	}
+
+	return RetryTransferTask200JSONResponse(s.jobToTransferItem(job)), nil
+}
+
+// the response of RetryTransferTask to a failed check of the submission of the retried transfers
+func retryTransferTaskFailure(failed failedTransferCheck) RetryTransferTaskResponseObject {
+	switch failed.status {
+	case http.StatusConflict:
+		return RetryTransferTask409JSONResponse{
+			Message: getPointerOrNil(failed.message),
+			Details: getPointerOrNil(failed.details),
+		}
+	case http.StatusTooManyRequests:
+		return RetryTransferTask429JSONResponse(*failed.quota)
+	case http.StatusServiceUnavailable:
+		return RetryTransferTask503JSONResponse{
+			Message: getPointerOrNil(failed.message),
+			Details: getPointerOrNil(failed.details),
+		}
+	default:
+		return RetryTransferTask500JSONResponse{
+			Message: getPointerOrNil(failed.message),
+			Details: getPointerOrNil(failed.details),
+		}
+	}
+}
@@ -1,11 +1,13 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"text/template"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -65,6 +67,12 @@ type GroupTemplateData struct {
 	FacilityName string
 }
 
+func executeGroupTemplate(groupTemplate *template.Template, facilityName string) (string, error) {
+	var groupBuf bytes.Buffer
+	err := groupTemplate.Execute(&groupBuf, GroupTemplateData{FacilityName: facilityName})
+	return groupBuf.String(), err
+}
+
 func ScicatTokenAuthMiddleware(scicatUrl string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		scicatApiKey := c.Request.Header.Get("SciCat-API-Key")
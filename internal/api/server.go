@@ -7,6 +7,7 @@ import (
 
 	"fmt"
 
+	"github.com/SwissOpenEM/globus-transfer-service/internal/metrics"
 	"github.com/gin-gonic/gin"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -15,7 +16,7 @@ import (
 //go:embed openapi.yaml
 var swaggerYAML embed.FS
 
-func NewServer(api *ServerHandler, port uint, scicatUrl string) (*http.Server, error) {
+func NewServer(api *ServerHandler, port uint, scicatUrl string, m *metrics.Metrics) (*http.Server, error) {
 	r := gin.New()
 
 	r.GET("/openapi.yaml", func(c *gin.Context) {
@@ -24,7 +25,13 @@ func NewServer(api *ServerHandler, port uint, scicatUrl string) (*http.Server, e
 
 	r.GET("/docs/*any", ginSwagger.WrapHandler(swaggerfiles.Handler, ginSwagger.URL("/openapi.yaml")))
 
+	// probes are registered before the auth middleware, as they're called without a SciCat token
+	r.GET("/healthz", healthzHandler)
+	r.GET("/readyz", readyzHandler(api))
+	r.GET("/metrics", gin.WrapH(m.Handler()))
+
 	r.Use(
+		TransferRequestMetricsMiddleware(m, api.facilityCollectionIDs),
 		ScicatTokenAuthMiddleware(scicatUrl),
 	)
 
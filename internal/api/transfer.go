@@ -1,15 +1,9 @@
 package api
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
-	"net/url"
-	"path"
 	"reflect"
 	"slices"
 
@@ -27,20 +21,6 @@ func (s ServerHandler) PostTransferTask(ctx context.Context, request PostTransfe
 		}, nil
 	}
 
-	// check facility id's and fetch collection id's
-	sourceCollectionID, ok := s.facilityCollectionIDs[request.Params.SourceFacility]
-	if !ok {
-		return PostTransferTask403JSONResponse{
-			Message: getPointerOrNil("invalid source facility"),
-		}, nil
-	}
-	destCollectionID, ok := s.facilityCollectionIDs[request.Params.DestFacility]
-	if !ok {
-		return PostTransferTask403JSONResponse{
-			Message: getPointerOrNil("invalid destination facility"),
-		}, nil
-	}
-
 	u, ok := ginCtx.Get("scicatUser")
 	if !ok {
 		return PostTransferTask500JSONResponse{
@@ -57,125 +37,6 @@ func (s ServerHandler) PostTransferTask(ctx context.Context, request PostTransfe
 		}, nil
 	}
 
-	// fetch related dataset
-	datasetUrl, err := url.JoinPath(s.scicatUrl, "api", "v3", "datasets", url.QueryEscape(request.Params.ScicatPid))
-	if err != nil {
-		return PostTransferTask500JSONResponse{
-			Message: getPointerOrNil("couldn't create dataset request url"),
-			Details: getPointerOrNil(err.Error()),
-		}, nil
-	}
-
-	datasetReq, err := http.NewRequest("GET", datasetUrl, nil)
-	if err != nil {
-		return PostTransferTask500JSONResponse{
-			Message: getPointerOrNil("couldn't generate dataset request"),
-			Details: getPointerOrNil(err.Error()),
-		}, nil
-	}
-	datasetReq.Header.Set("Authorization", "Bearer "+scicatUser.ScicatToken)
-
-	datasetResp, err := http.DefaultClient.Do(datasetReq)
-	if err != nil {
-		return PostTransferTask500JSONResponse{
-			Message: getPointerOrNil("couldn't send dataset request to scicat backend"),
-			Details: getPointerOrNil(err.Error()),
-		}, nil
-	}
-	defer datasetResp.Body.Close()
-
-	if datasetResp.StatusCode != 200 {
-		body, _ := io.ReadAll(datasetResp.Body)
-		return PostTransferTask400JSONResponse{
-			GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
-				Message: getPointerOrNil("the dataset with the given pid does not exist or you don't have access rights to it"),
-				Details: getPointerOrNil(fmt.Sprintf("response status '%d', body '%s'", datasetResp.StatusCode, string(body))),
-			},
-		}, nil
-	}
-
-	datasetRespBody, err := io.ReadAll(datasetResp.Body)
-	if err != nil {
-		return PostTransferTask500JSONResponse{
-			Message: getPointerOrNil("failed to read response body"),
-			Details: getPointerOrNil(err.Error()),
-		}, nil
-	}
-
-	var dataset ScicatDataset
-	err = json.Unmarshal(datasetRespBody, &dataset)
-	if err != nil {
-		return PostTransferTask500JSONResponse{
-			Message: getPointerOrNil("failed to unmarshal response body"),
-			Details: getPointerOrNil(err.Error()),
-		}, nil
-	}
-
-	// check for required group memberships
-	var srcGroupBuf bytes.Buffer
-	err = s.srcGroupTemplate.Execute(&srcGroupBuf, GroupTemplateData{FacilityName: request.Params.SourceFacility})
-	if err != nil {
-		return PostTransferTask500JSONResponse{
-			Message: getPointerOrNil("group templating failed with source facility"),
-			Details: getPointerOrNil(err.Error()),
-		}, nil
-	}
-
-	var dstGroupBuf bytes.Buffer
-	err = s.dstGroupTemplate.Execute(&dstGroupBuf, GroupTemplateData{FacilityName: request.Params.DestFacility})
-	if err != nil {
-		return PostTransferTask500JSONResponse{
-			Message: getPointerOrNil("group templating failed with destination facility"),
-			Details: getPointerOrNil(err.Error()),
-		}, nil
-	}
-
-	requiredGroups := []string{srcGroupBuf.String(), dstGroupBuf.String(), dataset.OwnerGroup}
-	missingGroups := []string{}
-	for _, group := range requiredGroups {
-		if !slices.Contains(scicatUser.Profile.AccessGroups, group) {
-			missingGroups = append(missingGroups, group)
-		}
-	}
-
-	if len(missingGroups) > 0 {
-		return PostTransferTask401JSONResponse{
-			Message: getPointerOrNil("you don't have the required access groups to request this transfer"),
-			Details: getPointerOrNil(fmt.Sprintf("missing groups: '%v'", missingGroups)),
-		}, nil
-	}
-
-	// request the transfer
-	if s.taskPool.IsQueueSizeLimited() {
-		s.addTaskMutex.Lock()
-		defer s.addTaskMutex.Unlock()
-		if !s.taskPool.CanSubmitJob() {
-			return PostTransferTask503JSONResponse{
-				Message: getPointerOrNil("the task queue is currently full, try again later..."),
-			}, nil
-		}
-	}
-
-	params := destPathParams{
-		DatasetFolder: path.Base(dataset.SourceFolder),
-		SourceFolder:  dataset.SourceFolder,
-		Pid:           request.Params.ScicatPid,
-		PidShort:      path.Base(request.Params.ScicatPid),
-		PidPrefix:     path.Dir(request.Params.ScicatPid),
-		PidEncoded:    url.PathEscape(request.Params.ScicatPid),
-		Username:      scicatUser.Profile.Username,
-	}
-
-	sourcePath := dataset.SourceFolder
-	destPath, err := s.dstPathTemplate.Execute(params)
-	if err != nil {
-		return PostTransferTask500JSONResponse{
-			Message: getPointerOrNil("couldn't template destination folder for the transfer"),
-			Details: getPointerOrNil(err.Error()),
-		}, nil
-	}
-
-	var globusResult globus.TransferResult
 	if request.Body == nil {
 		return PostTransferTask400JSONResponse{
 			GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
@@ -184,57 +45,61 @@ func (s ServerHandler) PostTransferTask(ctx context.Context, request PostTransfe
 		}, nil
 	}
 
-	if request.Body.FileList != nil {
-		// use filelist
-		paths := make([]string, len(*request.Body.FileList))
-		isSymlinks := make([]bool, len(*request.Body.FileList))
-		for i, file := range *request.Body.FileList {
-			paths[i] = file.Path
-			isSymlinks[i] = file.IsSymlink
-		}
-		globusResult, err = s.globusClient.TransferFileList(sourceCollectionID, sourcePath, destCollectionID, destPath, paths, isSymlinks, false)
-	} else {
-		// sync folders through globus
-		globusResult, err = s.globusClient.TransferFolderSync(sourceCollectionID, sourcePath, destCollectionID, destPath, false)
+	transfer, failed, unlock := s.checkTransferRequest(scicatUser, request.Params, PostTransferTaskJSONBody(*request.Body), false)
+	defer unlock()
+	if len(failed) > 0 {
+		return postTransferTaskFailure(failed[0]), nil
 	}
-	if err != nil {
-		return PostTransferTask400JSONResponse{
-			GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
-				Message: getPointerOrNil("can't request globus transfer"),
-				Details: getPointerOrNil(err.Error()),
-			},
-		}, nil
-	}
-
-	serviceUserToken, err := s.scicatServiceUser.GetToken()
-	if err != nil {
-		_, _ = s.globusClient.TransferCancelTaskByID(globusResult.TaskId) // attempt to cancel transfer
-		return PostTransferTask500JSONResponse{
-			Message: getPointerOrNil("service user login failed"),
-			Details: getPointerOrNil(err.Error()),
+	if transfer.idempotentJob != nil {
+		return PostTransferTask200JSONResponse{
+			JobId: transfer.idempotentJob.ID,
 		}, nil
 	}
 
+	// request the transfer
 	// TODO: replace the service user token with the current user's token if it becomes possible to create the scicatJob as one's own user
 	//   , which will happen once the required changes are merged into BE SciCat. If the changes will still not allow this, just
 	//   remove this TODO.
-	scicatJob, err := tasks.CreateGlobusTransferScicatJob(s.scicatUrl, serviceUserToken, dataset.OwnerGroup, params.Pid, "")
+	scicatJob, err := s.taskPool.SubmitTransfer(scicatUser.Profile.Username, transfer.dataset.OwnerGroup, jobs.JobParams{
+		DatasetList:         []jobs.Dataset{jobDataset(request.Params.ScicatPid, transfer.dataset.Size, transfer.sourcePath, transfer.destPath, request.Body.FileList)},
+		SourceFacility:      request.Params.SourceFacility,
+		DestinationFacility: request.Params.DestFacility,
+		IdempotencyKey:      transfer.idempotencyKey,
+		TransferOptions:     &transfer.options,
+		Priority:            transfer.priority,
+	}, []globus.Transfer{
+		globusTransfer(transfer.sourceCollectionID, transfer.sourcePath, transfer.destCollectionID, transfer.destPath, request.Body.FileList, transfer.options),
+	})
 	if err != nil {
-		_, _ = s.globusClient.TransferCancelTaskByID(globusResult.TaskId) // attempt to cancel transfer
 		return PostTransferTask500JSONResponse{
-			Message: getPointerOrNil("failed creating transfer job in SciCat"),
+			Message: getPointerOrNil(submissionFailure(err, "failed creating transfer job in SciCat")),
 			Details: getPointerOrNil(err.Error()),
 		}, nil
 	}
 
-	s.taskPool.AddTransferTask(globusResult.TaskId, request.Params.ScicatPid, scicatJob.ID)
-
 	// return response
 	return PostTransferTask200JSONResponse{
 		JobId: scicatJob.ID,
 	}, nil
 }
 
+// describes the stage at which the submission of a transfer failed, where scicatFailure describes a failure to
+// create or update its SciCat job
+func submissionFailure(err error, scicatFailure string) string {
+	submissionErr := &tasks.SubmissionError{}
+	if !errors.As(err, &submissionErr) {
+		return "failed submitting the transfer"
+	}
+	switch submissionErr.Stage {
+	case tasks.StageScicatLogin:
+		return "service user login failed"
+	case tasks.StageLocalStore:
+		return "failed recording the transfer in the local store"
+	default:
+		return scicatFailure
+	}
+}
+
 func (s ServerHandler) DeleteTransferTask(ctx context.Context, req DeleteTransferTaskRequestObject) (DeleteTransferTaskResponseObject, error) {
 	ginCtx, ok := ctx.(*gin.Context)
 	if !ok {
@@ -306,3 +171,185 @@ func (s ServerHandler) DeleteTransferTask(ctx context.Context, req DeleteTransfe
 
 	return DeleteTransferTask200Response{}, nil
 }
+
+func (s ServerHandler) GetTransferTask(ctx context.Context, req GetTransferTaskRequestObject) (GetTransferTaskResponseObject, error) {
+	ginCtx, ok := ctx.(*gin.Context)
+	if !ok {
+		return GetTransferTask500JSONResponse{
+			Message: getPointerOrNil("context error"),
+		}, nil
+	}
+
+	// fetch scicat user
+	u, ok := ginCtx.Get("scicatUser")
+	if !ok {
+		return GetTransferTask500JSONResponse{
+			Message: getPointerOrNil("no user was found"),
+		}, nil
+	}
+
+	scicatUser, ok := u.(User)
+	if !ok {
+		return GetTransferTask500JSONResponse{
+			Message: getPointerOrNil("invalid user in context"),
+			Details: getPointerOrNil(fmt.Sprintf("type found: '%s'", reflect.TypeOf(u))),
+		}, nil
+	}
+
+	serviceToken, err := s.scicatServiceUser.GetToken()
+	if err != nil {
+		return GetTransferTask500JSONResponse{
+			Message: getPointerOrNil("couldn't access SciCat"),
+			Details: getPointerOrNil(fmt.Sprintf("SciCat token renewal failed: %s", err.Error())),
+		}, nil
+	}
+
+	job, err := jobs.GetJobById(s.scicatUrl, serviceToken, req.ScicatJobId)
+	jobNotFoundErr := &jobs.JobNotFoundErr{}
+	if err != nil && !errors.As(err, &jobNotFoundErr) {
+		// SciCat might only be unavailable for a moment, so the job is looked up in the local store instead
+		if storedJob, ok, storeErr := s.taskPool.GetStoredTransferJob(req.ScicatJobId); storeErr == nil && ok {
+			job, err = storedJob, nil
+		}
+	}
+	if err != nil {
+		return GetTransferTask400JSONResponse{
+			GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
+				Message: getPointerOrNil("failed to request job from SciCat"),
+				Details: getPointerOrNil(err.Error()),
+			},
+		}, nil
+	}
+
+	if !canViewJob(scicatUser, job) {
+		return GetTransferTask403JSONResponse{
+			Message: getPointerOrNil("you don't have the right to view this job"),
+		}, nil
+	}
+
+	return GetTransferTask200JSONResponse(s.jobToTransferItem(job)), nil
+}
+
+// converts a SciCat transfer job into a TransferItem, preferring the live state
+// of the task pool as it has fresher information as long as it holds the task
+func (s ServerHandler) jobToTransferItem(job jobs.ScicatJob) TransferItem {
+	if liveStatus, ok := s.taskPool.GetTransferTaskStatus(job.ID); ok {
+		return liveTransferItem(job, liveStatus)
+	}
+	item := transferItem(job, job.JobResultObject, job.StatusMessage)
+	if position, priority, ok := s.taskPool.QueuePosition(job.ID); ok {
+		item.QueuePosition = &position
+		item.Priority = toTransferPriority(priority)
+	}
+	return item
+}
+
+// converts a SciCat transfer job into a TransferItem with the given live status of its task
+func liveTransferItem(job jobs.ScicatJob, liveStatus jobs.JobResultObject) TransferItem {
+	message := string(liveStatus.Status)
+	if liveStatus.Status == jobs.Waiting {
+		message = "waiting for the first poll of the transfer"
+	}
+	return transferItem(job, liveStatus, message)
+}
+
+func transferItem(job jobs.ScicatJob, jobResult jobs.JobResultObject, message string) TransferItem {
+	if jobResult.Status == jobs.Stalled {
+		message = tasks.StalledMessage(jobResult.Datasets)
+	}
+	if jobResult.Error != "" {
+		message = jobResult.Error
+	}
+
+	datasetPids := make([]string, len(job.JobParams.DatasetList))
+	for i, dataset := range job.JobParams.DatasetList {
+		datasetPids[i] = dataset.Pid
+	}
+
+	datasets := make([]DatasetTransferItem, len(jobResult.Datasets))
+	for i, dataset := range jobResult.Datasets {
+		bytesTransferred := int(dataset.BytesTransferred)
+		filesTransferred := int(dataset.FilesTransferred)
+		filesTotal := int(dataset.FilesTotal)
+		filesFailed := int(dataset.FilesFailed)
+		var failedFiles *[]SkippedFile
+		if len(dataset.FailedFiles) > 0 {
+			files := make([]SkippedFile, len(dataset.FailedFiles))
+			for j, file := range dataset.FailedFiles {
+				files[j] = SkippedFile{
+					SourcePath:   file.Path,
+					ErrorCode:    file.ErrorCode,
+					ErrorDetails: getPointerOrNil(file.ErrorDetails),
+				}
+			}
+			failedFiles = &files
+		}
+		datasetMessage := dataset.Error
+		if dataset.Status == jobs.Stalled {
+			datasetMessage = dataset.StalledReason
+		}
+		datasets[i] = DatasetTransferItem{
+			ScicatPid:        dataset.Pid,
+			Status:           toTransferStatus(dataset.Status),
+			Message:          getPointerOrNil(datasetMessage),
+			BytesTransferred: &bytesTransferred,
+			FilesTransferred: &filesTransferred,
+			FilesTotal:       &filesTotal,
+			FilesFailed:      &filesFailed,
+			FailedFiles:      failedFiles,
+			StalledSince:     dataset.StalledSince,
+		}
+	}
+
+	bytesTransferred := int(jobResult.BytesTransferred)
+	filesTransferred := int(jobResult.FilesTransferred)
+	filesTotal := int(jobResult.FilesTotal)
+	filesFailed := int(jobResult.FilesFailed)
+	return TransferItem{
+		TransferId:          job.ID,
+		Status:              toTransferStatus(jobResult.Status),
+		Message:             getPointerOrNil(message),
+		BytesTransferred:    &bytesTransferred,
+		FilesTransferred:    &filesTransferred,
+		FilesTotal:          &filesTotal,
+		FilesFailed:         &filesFailed,
+		DatasetPids:         &datasetPids,
+		SourceFacility:      getPointerOrNil(job.JobParams.SourceFacility),
+		DestinationFacility: getPointerOrNil(job.JobParams.DestinationFacility),
+		CreatedAt:           getPointerOrNil(job.CreatedAt),
+		Datasets:            &datasets,
+		Retrieval:           getPointerOrNil(job.JobParams.Retrieval),
+		Deadline:            jobResult.Deadline,
+		Priority:            toTransferPriority(job.JobParams.Priority),
+	}
+}
+
+// the priority of a job, jobs created before priorities were introduced have the normal priority
+func toTransferPriority(priority jobs.Priority) *TransferPriority {
+	if priority == "" {
+		priority = jobs.PriorityNormal
+	}
+	transferPriority := TransferPriority(priority)
+	return &transferPriority
+}
+
+func toTransferStatus(status jobs.JobStatus) TransferStatus {
+	switch status {
+	case jobs.Submitting:
+		return Submitting
+	case jobs.Waiting:
+		return Waiting
+	case jobs.Transferring:
+		return Transferring
+	case jobs.Stalled:
+		return Stalled
+	case jobs.Finished:
+		return Finished
+	case jobs.Failed:
+		return Failed
+	case jobs.Cancelled:
+		return Cancelled
+	default:
+		return InvalidStatus
+	}
+}
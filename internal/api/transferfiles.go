@@ -0,0 +1,130 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/SwissOpenEM/globus-transfer-service/internal/tasks"
+	"github.com/SwissOpenEM/globus-transfer-service/jobs"
+	"github.com/gin-gonic/gin"
+)
+
+// GetTransferTaskFiles lists the files transferred and skipped by the current globus task of each dataset of a transfer job
+func (s ServerHandler) GetTransferTaskFiles(ctx context.Context, req GetTransferTaskFilesRequestObject) (GetTransferTaskFilesResponseObject, error) {
+	ginCtx, ok := ctx.(*gin.Context)
+	if !ok {
+		return GetTransferTaskFiles500JSONResponse{
+			Message: getPointerOrNil("context error"),
+		}, nil
+	}
+
+	// fetch scicat user
+	u, ok := ginCtx.Get("scicatUser")
+	if !ok {
+		return GetTransferTaskFiles500JSONResponse{
+			Message: getPointerOrNil("no user was found"),
+		}, nil
+	}
+
+	scicatUser, ok := u.(User)
+	if !ok {
+		return GetTransferTaskFiles500JSONResponse{
+			Message: getPointerOrNil("invalid user in context"),
+			Details: getPointerOrNil(fmt.Sprintf("type found: '%s'", reflect.TypeOf(u))),
+		}, nil
+	}
+
+	serviceToken, err := s.scicatServiceUser.GetToken()
+	if err != nil {
+		return GetTransferTaskFiles500JSONResponse{
+			Message: getPointerOrNil("couldn't access SciCat"),
+			Details: getPointerOrNil(fmt.Sprintf("SciCat token renewal failed: %s", err.Error())),
+		}, nil
+	}
+
+	job, err := jobs.GetJobById(s.scicatUrl, serviceToken, req.ScicatJobId)
+	if err != nil {
+		return GetTransferTaskFiles400JSONResponse{
+			GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
+				Message: getPointerOrNil("failed to request job from SciCat"),
+				Details: getPointerOrNil(err.Error()),
+			},
+		}, nil
+	}
+
+	if !canViewJob(scicatUser, job) {
+		return GetTransferTaskFiles403JSONResponse{
+			Message: getPointerOrNil("you don't have the right to view this job"),
+		}, nil
+	}
+
+	if job.Type != jobs.GlobusTransferJobType {
+		return GetTransferTaskFiles400JSONResponse{
+			GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
+				Message: getPointerOrNil("the job is not a transfer job"),
+			},
+		}, nil
+	}
+
+	datasetTransfers, err := tasks.JobDatasetTransfers(job)
+	if err != nil {
+		return GetTransferTaskFiles400JSONResponse{
+			GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
+				Message: getPointerOrNil("the job has no globus tasks to list the files of"),
+				Details: getPointerOrNil(err.Error()),
+			},
+		}, nil
+	}
+
+	datasets := make([]DatasetTransferFiles, len(datasetTransfers))
+	for i, datasetTransfer := range datasetTransfers {
+		datasets[i] = DatasetTransferFiles{
+			ScicatPid:    datasetTransfer.Pid,
+			GlobusTaskId: datasetTransfer.GlobusTaskId,
+			Transferred:  []TransferredFile{},
+			Skipped:      []SkippedFile{},
+		}
+		// the datasets of queued jobs have no globus task yet
+		if datasetTransfer.GlobusTaskId == "" {
+			continue
+		}
+
+		transferred, err := tasks.TransferredFiles(s.globusClient, datasetTransfer.GlobusTaskId)
+		if err != nil {
+			return GetTransferTaskFiles500JSONResponse{
+				Message: getPointerOrNil(fmt.Sprintf("failed to fetch the transferred files of '%s' from globus", datasetTransfer.Pid)),
+				Details: getPointerOrNil(err.Error()),
+			}, nil
+		}
+		skipped, err := tasks.SkippedFiles(s.globusClient, datasetTransfer.GlobusTaskId)
+		if err != nil {
+			return GetTransferTaskFiles500JSONResponse{
+				Message: getPointerOrNil(fmt.Sprintf("failed to fetch the skipped files of '%s' from globus", datasetTransfer.Pid)),
+				Details: getPointerOrNil(err.Error()),
+			}, nil
+		}
+
+		datasets[i].Transferred = make([]TransferredFile, len(transferred))
+		datasets[i].Skipped = make([]SkippedFile, len(skipped))
+		for j, file := range transferred {
+			datasets[i].Transferred[j] = TransferredFile{
+				SourcePath:      file.SourcePath,
+				DestinationPath: file.DestinationPath,
+			}
+		}
+		for j, file := range skipped {
+			datasets[i].Skipped[j] = SkippedFile{
+				SourcePath:      file.SourcePath,
+				DestinationPath: getPointerOrNil(file.DestinationPath),
+				ErrorCode:       file.ErrorCode,
+				ErrorDetails:    getPointerOrNil(file.ErrorDetails),
+			}
+		}
+	}
+
+	return GetTransferTaskFiles200JSONResponse{
+		TransferId: job.ID,
+		Datasets:   datasets,
+	}, nil
+}
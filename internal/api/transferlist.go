@@ -0,0 +1,198 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/SwissOpenEM/globus-transfer-service/jobs"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultTransferListLimit = 20
+	maxTransferListLimit     = 100
+)
+
+var listableStatuses = []jobs.JobStatus{jobs.Submitting, jobs.Waiting, jobs.Transferring, jobs.Stalled, jobs.Finished, jobs.Failed, jobs.Cancelled}
+
+type transferListLimits struct {
+	Limit int    `json:"limit"`
+	Skip  int    `json:"skip"`
+	Order string `json:"order"`
+}
+
+type transferListFilter struct {
+	Where  map[string]any     `json:"where"`
+	Limits transferListLimits `json:"limits"`
+}
+
+func (s ServerHandler) GetTransferTasks(ctx context.Context, req GetTransferTasksRequestObject) (GetTransferTasksResponseObject, error) {
+	ginCtx, ok := ctx.(*gin.Context)
+	if !ok {
+		return GetTransferTasks500JSONResponse{
+			Message: getPointerOrNil("context error"),
+		}, nil
+	}
+
+	// fetch scicat user
+	u, ok := ginCtx.Get("scicatUser")
+	if !ok {
+		return GetTransferTasks500JSONResponse{
+			Message: getPointerOrNil("no user was found"),
+		}, nil
+	}
+
+	scicatUser, ok := u.(User)
+	if !ok {
+		return GetTransferTasks500JSONResponse{
+			Message: getPointerOrNil("invalid user in context"),
+			Details: getPointerOrNil(fmt.Sprintf("type found: '%s'", reflect.TypeOf(u))),
+		}, nil
+	}
+
+	where, err := s.transferListWhere(req.Params, scicatUser)
+	if err != nil {
+		return GetTransferTasks400JSONResponse{
+			GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
+				Message: getPointerOrNil("invalid filter parameters"),
+				Details: getPointerOrNil(err.Error()),
+			},
+		}, nil
+	}
+
+	limits := transferListLimits{
+		Limit: defaultTransferListLimit,
+		Skip:  0,
+		Order: "createdAt:desc",
+	}
+	if req.Params.Limit != nil {
+		if *req.Params.Limit < 1 || *req.Params.Limit > maxTransferListLimit {
+			return GetTransferTasks400JSONResponse{
+				GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
+					Message: getPointerOrNil(fmt.Sprintf("limit must be between 1 and %d", maxTransferListLimit)),
+				},
+			}, nil
+		}
+		limits.Limit = *req.Params.Limit
+	}
+	if req.Params.Skip != nil {
+		if *req.Params.Skip < 0 {
+			return GetTransferTasks400JSONResponse{
+				GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
+					Message: getPointerOrNil("skip can't be negative"),
+				},
+			}, nil
+		}
+		limits.Skip = *req.Params.Skip
+	}
+
+	listFilter, err := json.Marshal(transferListFilter{Where: where, Limits: limits})
+	if err != nil {
+		return GetTransferTasks500JSONResponse{
+			Message: getPointerOrNil("couldn't create job list filter"),
+			Details: getPointerOrNil(err.Error()),
+		}, nil
+	}
+
+	countFilter, err := json.Marshal(where)
+	if err != nil {
+		return GetTransferTasks500JSONResponse{
+			Message: getPointerOrNil("couldn't create job count filter"),
+			Details: getPointerOrNil(err.Error()),
+		}, nil
+	}
+
+	serviceToken, err := s.scicatServiceUser.GetToken()
+	if err != nil {
+		return GetTransferTasks500JSONResponse{
+			Message: getPointerOrNil("couldn't access SciCat"),
+			Details: getPointerOrNil(fmt.Sprintf("SciCat token renewal failed: %s", err.Error())),
+		}, nil
+	}
+
+	jobList, err := jobs.GetJobList(s.scicatUrl, serviceToken, string(listFilter))
+	if err != nil {
+		return GetTransferTasks400JSONResponse{
+			GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
+				Message: getPointerOrNil("failed to request job list from SciCat"),
+				Details: getPointerOrNil(err.Error()),
+			},
+		}, nil
+	}
+
+	total, err := jobs.GetJobCount(s.scicatUrl, serviceToken, string(countFilter))
+	if err != nil {
+		return GetTransferTasks400JSONResponse{
+			GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
+				Message: getPointerOrNil("failed to request job count from SciCat"),
+				Details: getPointerOrNil(err.Error()),
+			},
+		}, nil
+	}
+
+	transfers := make([]TransferItem, len(jobList))
+	for i, job := range jobList {
+		transfers[i] = s.jobToTransferItem(job)
+	}
+
+	return GetTransferTasks200JSONResponse{
+		Total:     int(total),
+		Transfers: transfers,
+	}, nil
+}
+
+// builds the "where" part of a SciCat job filter from the query parameters. Every value
+// is set as a plain string or time, so no query operators can be injected through them.
+func (s ServerHandler) transferListWhere(params GetTransferTasksParams, user User) (map[string]any, error) {
+	accessGroups := user.Profile.AccessGroups
+	if accessGroups == nil {
+		accessGroups = []string{}
+	}
+
+	conditions := []map[string]any{
+		{"type": jobs.GlobusTransferJobType},
+		{"$or": []map[string]any{
+			{"ownerUser": user.Profile.Username},
+			{"ownerGroup": map[string]any{"$in": accessGroups}},
+		}},
+	}
+
+	if params.Status != nil {
+		if !slices.Contains(listableStatuses, jobs.JobStatus(*params.Status)) {
+			return nil, fmt.Errorf("unknown status '%s', it must be one of %v", *params.Status, listableStatuses)
+		}
+		conditions = append(conditions, map[string]any{"jobResultObject.status": *params.Status})
+	}
+
+	if params.ScicatPid != nil {
+		conditions = append(conditions, map[string]any{"jobParams.datasetList.pid": *params.ScicatPid})
+	}
+
+	if params.SourceFacility != nil {
+		if _, ok := s.facilityCollectionIDs[*params.SourceFacility]; !ok {
+			return nil, fmt.Errorf("unknown source facility '%s'", *params.SourceFacility)
+		}
+		conditions = append(conditions, map[string]any{"jobParams.sourceFacility": *params.SourceFacility})
+	}
+
+	if params.DestFacility != nil {
+		if _, ok := s.facilityCollectionIDs[*params.DestFacility]; !ok {
+			return nil, fmt.Errorf("unknown destination facility '%s'", *params.DestFacility)
+		}
+		conditions = append(conditions, map[string]any{"jobParams.destinationFacility": *params.DestFacility})
+	}
+
+	if params.CreatedAfter != nil {
+		conditions = append(conditions, map[string]any{"createdAt": map[string]any{"$gte": params.CreatedAfter.UTC().Format(time.RFC3339Nano)}})
+	}
+
+	if params.CreatedBefore != nil {
+		conditions = append(conditions, map[string]any{"createdAt": map[string]any{"$lte": params.CreatedBefore.UTC().Format(time.RFC3339Nano)}})
+	}
+
+	return map[string]any{"$and": conditions}, nil
+}
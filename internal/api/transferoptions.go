@@ -0,0 +1,167 @@
+package api
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/SwissOpenEM/globus"
+	"github.com/SwissOpenEM/globus-transfer-service/internal/config"
+	"github.com/SwissOpenEM/globus-transfer-service/jobs"
+)
+
+// the globus sync levels by name, a transfer without a sync level copies every file
+var syncLevels = map[string]int{
+	string(Exists):   0,
+	string(Size):     1,
+	string(Mtime):    2,
+	string(Checksum): 3,
+}
+
+type TransferOptionNotAllowedError struct {
+	msg string
+}
+
+func (e *TransferOptionNotAllowedError) Error() string {
+	return e.msg
+}
+
+// checks that the sync levels of the configured transfer options are known to globus
+func validateTransferOptionRules(rules []config.TransferOptions) error {
+	for _, rule := range rules {
+		syncLevelValues := slices.Clone(rule.Allowed.SyncLevel)
+		if rule.Defaults.SyncLevel != nil {
+			syncLevelValues = append(syncLevelValues, *rule.Defaults.SyncLevel)
+		}
+		for _, syncLevel := range syncLevelValues {
+			if _, ok := syncLevels[syncLevel]; !ok {
+				return fmt.Errorf("unknown sync level '%s' in the transfer options of '%s' -> '%s'", syncLevel, rule.SourceFacility, rule.DestFacility)
+			}
+		}
+	}
+	return nil
+}
+
+// the configured transfer options of a pair of facilities, which are the ones of the first rule that matches them
+func (s ServerHandler) transferOptionRule(sourceFacility string, destFacility string) config.TransferOptions {
+	for _, rule := range s.transferOptionRules {
+		if (rule.SourceFacility == "" || rule.SourceFacility == sourceFacility) && (rule.DestFacility == "" || rule.DestFacility == destFacility) {
+			return rule
+		}
+	}
+	return config.TransferOptions{}
+}
+
+// resolves the options of a transfer between two facilities from the requested ones, which can be nil,
+// and the configuration. Returns a TransferOptionNotAllowedError if an option ends up with a value that
+// isn't allowed for the facilities.
+func (s ServerHandler) transferOptions(sourceFacility string, destFacility string, requested *TransferOptions) (jobs.TransferOptions, error) {
+	if requested == nil {
+		requested = &TransferOptions{}
+	}
+	rule := s.transferOptionRule(sourceFacility, destFacility)
+
+	var requestedSyncLevel *string
+	if requested.SyncLevel != nil {
+		syncLevel := string(*requested.SyncLevel)
+		requestedSyncLevel = &syncLevel
+	}
+
+	options := jobs.TransferOptions{}
+	var err error
+	if options.SyncLevel, err = resolveTransferOption("syncLevel", requestedSyncLevel, rule.Defaults.SyncLevel, rule.Allowed.SyncLevel); err != nil {
+		return options, err
+	}
+	if options.VerifyChecksum, err = resolveTransferOption("verifyChecksum", requested.VerifyChecksum, rule.Defaults.VerifyChecksum, rule.Allowed.VerifyChecksum); err != nil {
+		return options, err
+	}
+	if options.EncryptData, err = resolveTransferOption("encryptData", requested.EncryptData, rule.Defaults.EncryptData, rule.Allowed.EncryptData); err != nil {
+		return options, err
+	}
+	if options.PreserveTimestamp, err = resolveTransferOption("preserveTimestamp", requested.PreserveTimestamp, rule.Defaults.PreserveTimestamp, rule.Allowed.PreserveTimestamp); err != nil {
+		return options, err
+	}
+	if options.SkipSourceErrors, err = resolveTransferOption("skipSourceErrors", requested.SkipSourceErrors, rule.Defaults.SkipSourceErrors, rule.Allowed.SkipSourceErrors); err != nil {
+		return options, err
+	}
+	if options.FailOnQuotaErrors, err = resolveTransferOption("failOnQuotaErrors", requested.FailOnQuotaErrors, rule.Defaults.FailOnQuotaErrors, rule.Allowed.FailOnQuotaErrors); err != nil {
+		return options, err
+	}
+	if options.DeleteDestinationExtra, err = resolveTransferOption("deleteDestinationExtra", requested.DeleteDestinationExtra, rule.Defaults.DeleteDestinationExtra, rule.Allowed.DeleteDestinationExtra); err != nil {
+		return options, err
+	}
+	options.Label, _ = resolveTransferOption("label", requested.Label, rule.Defaults.Label, nil)
+	if options.Deadline, err = transferDeadline(rule, requested.Deadline); err != nil {
+		return options, err
+	}
+	if options.Deadline == nil {
+		options.MaxDuration = rule.MaxDuration
+	}
+	return options, nil
+}
+
+// the deadline of a transfer is the requested one, which can't be later than the maximum duration of the rule from
+// now. Transfers without a requested deadline get the maximum duration from when they leave the queue instead, as
+// they can wait in the queue for longer than that.
+func transferDeadline(rule config.TransferOptions, requested *time.Time) (*time.Time, error) {
+	if requested == nil {
+		return nil, nil
+	}
+	now := time.Now()
+	if !requested.After(now) {
+		return nil, &TransferOptionNotAllowedError{
+			msg: fmt.Sprintf("the deadline '%s' has already passed", requested.Format(time.RFC3339)),
+		}
+	}
+	if rule.MaxDuration == 0 {
+		return requested, nil
+	}
+
+	latest := now.Add(time.Duration(rule.MaxDuration) * time.Second)
+	if requested.After(latest) {
+		return nil, &TransferOptionNotAllowedError{
+			msg: fmt.Sprintf("the deadline '%s' is later than the latest one allowed between these facilities, '%s'", requested.Format(time.RFC3339), latest.Format(time.RFC3339)),
+		}
+	}
+	return requested, nil
+}
+
+// the value of an option is the requested one, or else its default, or else the first allowed value
+func resolveTransferOption[T comparable](name string, requested *T, defaultValue *T, allowed []T) (T, error) {
+	var value T
+	switch {
+	case requested != nil:
+		value = *requested
+	case defaultValue != nil:
+		value = *defaultValue
+	case len(allowed) > 0:
+		value = allowed[0]
+	}
+
+	if len(allowed) > 0 && !slices.Contains(allowed, value) {
+		return value, &TransferOptionNotAllowedError{
+			msg: fmt.Sprintf("'%v' is not an allowed value of '%s' between these facilities, allowed values: %v", value, name, allowed),
+		}
+	}
+	return value, nil
+}
+
+// sets the options on the globus transfer
+func applyTransferOptions(transfer *globus.Transfer, options jobs.TransferOptions) {
+	if syncLevel, ok := syncLevels[options.SyncLevel]; ok {
+		transfer.SyncLevel = &syncLevel
+	}
+	transfer.VerifyChecksum = &options.VerifyChecksum
+	transfer.EncryptData = &options.EncryptData
+	transfer.PreserveTimestamp = &options.PreserveTimestamp
+	transfer.SkipSourceErrors = &options.SkipSourceErrors
+	transfer.FailOnQuotaErrors = &options.FailOnQuotaErrors
+	transfer.DeleteDestinationExtra = &options.DeleteDestinationExtra
+	if options.Label != "" {
+		transfer.Label = &options.Label
+	}
+	if options.Deadline != nil {
+		deadline := options.Deadline.UTC().Format(time.RFC3339)
+		transfer.Deadline = &deadline
+	}
+}
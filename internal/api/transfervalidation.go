@@ -0,0 +1,353 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/SwissOpenEM/globus-transfer-service/jobs"
+	"github.com/gin-gonic/gin"
+)
+
+// a check of a transfer request that failed, with the status that requesting the transfer responds with
+type failedTransferCheck struct {
+	check   string
+	status  int
+	message string
+	details string
+	// the quota that would be exceeded, for the failures of the quota check
+	quota *QuotaExceeded
+}
+
+// the transfer resolved by the checks of its request, as far as they could resolve it
+type checkedTransfer struct {
+	sourceCollectionID string
+	destCollectionID   string
+	dataset            ScicatDataset
+	sourcePath         string
+	destPath           string
+	options            jobs.TransferOptions
+	priority           jobs.Priority
+	idempotencyKey     string
+	// the job created by an earlier request with the same idempotency key, which is returned instead of
+	// starting another transfer
+	idempotentJob *jobs.ScicatJob
+}
+
+// a submission of transfers, as far as the checks shared by every request that submits transfers need to know it
+type submissionCheck struct {
+	// the datasets whose ongoing transfers to the destination facility are looked for
+	pids         []string
+	destFacility string
+	ownerUser    string
+	// the owner group of the datasets, the quotas aren't checked while it's unknown
+	ownerGroup string
+	size       int64
+}
+
+// checkSubmission runs the checks shared by every request that submits transfers: none of the datasets can already
+// be transferred to the destination facility by another job, the queue has to have room for the job and the
+// submission can't exceed the quotas of its owners. The caller has to hold the submission locks of the datasets.
+// Unless it's a dry run, the checks stop at the first failing one and keep the queue locked until unlock is called,
+// so that concurrent submissions can't all pass them before any of them is queued.
+func (s ServerHandler) checkSubmission(serviceUserToken string, submission submissionCheck, dryRun bool) (failed []failedTransferCheck, unlock func()) {
+	unlock = func() {}
+	// records a failed check and returns whether the checks stop there
+	fail := func(failedCheck failedTransferCheck) bool {
+		failed = append(failed, failedCheck)
+		return !dryRun
+	}
+
+	for _, pid := range submission.pids {
+		ongoingJob, found, err := s.findOngoingTransfer(serviceUserToken, pid, submission.destFacility)
+		if err != nil {
+			if fail(failedTransferCheck{check: "duplicate", status: http.StatusInternalServerError, message: fmt.Sprintf("failed to look for ongoing transfers of the dataset '%s'", pid), details: err.Error()}) {
+				return
+			}
+		} else if found {
+			if fail(failedTransferCheck{check: "duplicate", status: http.StatusConflict, message: fmt.Sprintf("the dataset '%s' is already being transferred to the destination facility", pid), details: fmt.Sprintf("job id: '%s'", ongoingJob.ID)}) {
+				return
+			}
+		}
+	}
+
+	if s.taskPool.IsQueueSizeLimited() || s.hasQuotas() {
+		if !dryRun {
+			s.addTaskMutex.Lock()
+			unlock = s.addTaskMutex.Unlock
+		}
+		if !s.taskPool.CanSubmitJob() {
+			if fail(failedTransferCheck{check: "queue", status: http.StatusServiceUnavailable, message: "the service is already tracking as many transfers as it can, try again later..."}) {
+				return
+			}
+		}
+	}
+
+	if submission.ownerGroup != "" {
+		exceeded, err := s.exceededQuota(submission.ownerUser, submission.ownerGroup, submission.size)
+		if err != nil {
+			fail(failedTransferCheck{check: "quota", status: http.StatusInternalServerError, message: "failed to check the quotas of the submission", details: err.Error()})
+		} else if exceeded != nil {
+			fail(failedTransferCheck{check: "quota", status: http.StatusTooManyRequests, message: exceeded.Message, quota: exceeded})
+		}
+	}
+	return
+}
+
+// checkTransferRequest runs the checks of a request for the transfer of a single dataset, in the order in which
+// PostTransferTask reports them. A request stops at the first failing check and keeps the locks of the submission
+// until unlock is called, while a dry run runs every check that doesn't depend on one that failed, without locking.
+func (s ServerHandler) checkTransferRequest(scicatUser User, params PostTransferTaskParams, body PostTransferTaskJSONBody, dryRun bool) (transfer checkedTransfer, failed []failedTransferCheck, unlock func()) {
+	unlocks := []func(){}
+	unlock = func() {
+		for i := len(unlocks) - 1; i >= 0; i-- {
+			unlocks[i]()
+		}
+	}
+	// records a failed check and returns whether the checks stop there
+	fail := func(check string, status int, message string, details string) bool {
+		failed = append(failed, failedTransferCheck{check: check, status: status, message: message, details: details})
+		return !dryRun
+	}
+
+	// check facility id's and fetch collection id's
+	requiredGroups := []string{}
+	if sourceCollectionID, ok := s.facilityCollectionIDs[params.SourceFacility]; ok {
+		transfer.sourceCollectionID = sourceCollectionID
+	} else if fail("sourceFacility", http.StatusForbidden, "invalid source facility", "") {
+		return
+	}
+	if destCollectionID, ok := s.facilityCollectionIDs[params.DestFacility]; ok {
+		transfer.destCollectionID = destCollectionID
+	} else if fail("destFacility", http.StatusForbidden, "invalid destination facility", "") {
+		return
+	}
+
+	// fetch related dataset
+	dataset, err := fetchScicatDataset(s.scicatUrl, scicatUser.ScicatToken, params.ScicatPid)
+	datasetFetched := err == nil
+	if err != nil {
+		notAccessibleErr := &DatasetNotAccessibleError{}
+		if errors.As(err, &notAccessibleErr) {
+			if fail("dataset", http.StatusBadRequest, "the dataset with the given pid does not exist or you don't have access rights to it", notAccessibleErr.Error()) {
+				return
+			}
+		} else if fail("dataset", http.StatusInternalServerError, "failed to fetch the dataset", err.Error()) {
+			return
+		}
+	} else {
+		transfer.dataset = dataset
+		transfer.sourcePath = dataset.SourceFolder
+	}
+
+	// check for required group memberships, only with the groups that could be determined
+	if transfer.sourceCollectionID != "" {
+		srcGroup, err := executeGroupTemplate(s.srcGroupTemplate, params.SourceFacility)
+		if err == nil {
+			requiredGroups = append(requiredGroups, srcGroup)
+		} else if fail("groups", http.StatusInternalServerError, "group templating failed with source facility", err.Error()) {
+			return
+		}
+	}
+	if transfer.destCollectionID != "" {
+		dstGroup, err := executeGroupTemplate(s.dstGroupTemplate, params.DestFacility)
+		if err == nil {
+			requiredGroups = append(requiredGroups, dstGroup)
+		} else if fail("groups", http.StatusInternalServerError, "group templating failed with destination facility", err.Error()) {
+			return
+		}
+	}
+	if datasetFetched {
+		requiredGroups = append(requiredGroups, dataset.OwnerGroup)
+	}
+	if missingGroups := missingGroups(scicatUser, requiredGroups...); len(missingGroups) > 0 {
+		if fail("groups", http.StatusUnauthorized, "you don't have the required access groups to request this transfer", fmt.Sprintf("missing groups: '%v'", missingGroups)) {
+			return
+		}
+	}
+
+	// the dataset can't be transferred again while another job transfers it to the same facility, unless the
+	// request repeats the one that started that job
+	submission := submissionCheck{
+		destFacility: params.DestFacility,
+		ownerUser:    scicatUser.Profile.Username,
+	}
+	if datasetFetched {
+		submission.ownerGroup = dataset.OwnerGroup
+		submission.size = dataset.Size
+	}
+	serviceUserToken, err := s.scicatServiceUser.GetToken()
+	if err != nil {
+		if fail("duplicate", http.StatusInternalServerError, "service user login failed", err.Error()) {
+			return
+		}
+	} else {
+		if params.IdempotencyKey != nil {
+			transfer.idempotencyKey = *params.IdempotencyKey
+		}
+		// repeated requests for the same transfer are handled one at a time, so that only the first one starts it
+		if !dryRun {
+			unlocks = append(unlocks, s.lockSubmission(scicatUser.Profile.Username, transfer.idempotencyKey, []string{params.ScicatPid}, params.DestFacility))
+		}
+
+		if transfer.idempotencyKey != "" {
+			job, found, err := s.findIdempotentTransfer(serviceUserToken, scicatUser.Profile.Username, transfer.idempotencyKey)
+			if err != nil {
+				if fail("idempotencyKey", http.StatusInternalServerError, "failed to look for the job of the idempotency key", err.Error()) {
+					return
+				}
+			} else if found {
+				if !isSameTransfer(job, []string{params.ScicatPid}, params.SourceFacility, params.DestFacility) {
+					if fail("idempotencyKey", http.StatusConflict, "the idempotency key was already used for a different transfer request", fmt.Sprintf("job id: '%s'", job.ID)) {
+						return
+					}
+				} else {
+					// the request returns the job of the first one, so none of the other checks apply
+					transfer.idempotentJob = &job
+					return
+				}
+			}
+		}
+
+		submission.pids = []string{params.ScicatPid}
+	}
+
+	submissionFailed, unlockQueue := s.checkSubmission(serviceUserToken, submission, dryRun)
+	unlocks = append(unlocks, unlockQueue)
+	failed = append(failed, submissionFailed...)
+	if len(submissionFailed) > 0 && !dryRun {
+		return
+	}
+
+	if datasetFetched {
+		destPath, err := s.datasetDestinationPath(dataset, params.ScicatPid, scicatUser.Profile.Username)
+		if err == nil {
+			transfer.destPath = destPath
+		} else if fail("destPath", http.StatusInternalServerError, "couldn't template destination folder for the transfer", err.Error()) {
+			return
+		}
+	}
+
+	if transfer.options, err = s.transferOptions(params.SourceFacility, params.DestFacility, body.Options); err != nil {
+		if fail("options", http.StatusBadRequest, "the requested transfer options are not allowed", err.Error()) {
+			return
+		}
+	}
+
+	if transfer.priority, err = s.transferPriority(scicatUser, params.SourceFacility, params.DestFacility, body.Priority); err != nil {
+		unknownErr := &UnknownPriorityError{}
+		notAllowedErr := &PriorityNotAllowedError{}
+		if errors.As(err, &unknownErr) {
+			if fail("priority", http.StatusBadRequest, "the requested priority doesn't exist", err.Error()) {
+				return
+			}
+		} else if errors.As(err, &notAllowedErr) {
+			if fail("priority", http.StatusForbidden, "you don't have the right to request this priority", err.Error()) {
+				return
+			}
+		} else if fail("priority", http.StatusInternalServerError, "group templating failed with the requested priority", err.Error()) {
+			return
+		}
+	}
+
+	if err := checkFileList(body.FileList); err != nil {
+		fail("fileList", http.StatusBadRequest, "the requested file list is invalid", err.Error())
+	}
+	return
+}
+
+// the response of PostTransferTask to a failed check of its request
+func postTransferTaskFailure(failed failedTransferCheck) PostTransferTaskResponseObject {
+	switch failed.status {
+	case http.StatusBadRequest:
+		return PostTransferTask400JSONResponse{
+			GeneralErrorResponseJSONResponse: GeneralErrorResponseJSONResponse{
+				Message: getPointerOrNil(failed.message),
+				Details: getPointerOrNil(failed.details),
+			},
+		}
+	case http.StatusUnauthorized:
+		return PostTransferTask401JSONResponse{
+			Message: getPointerOrNil(failed.message),
+			Details: getPointerOrNil(failed.details),
+		}
+	case http.StatusForbidden:
+		return PostTransferTask403JSONResponse{
+			Message: getPointerOrNil(failed.message),
+			Details: getPointerOrNil(failed.details),
+		}
+	case http.StatusConflict:
+		return PostTransferTask409JSONResponse{
+			Message: getPointerOrNil(failed.message),
+			Details: getPointerOrNil(failed.details),
+		}
+	case http.StatusTooManyRequests:
+		return PostTransferTask429JSONResponse(*failed.quota)
+	case http.StatusServiceUnavailable:
+		return PostTransferTask503JSONResponse{
+			Message: getPointerOrNil(failed.message),
+			Details: getPointerOrNil(failed.details),
+		}
+	default:
+		return PostTransferTask500JSONResponse{
+			Message: getPointerOrNil(failed.message),
+			Details: getPointerOrNil(failed.details),
+		}
+	}
+}
+
+// ValidateTransferTask runs the checks of PostTransferTask without requesting anything. Unlike PostTransferTask,
+// it doesn't stop at the first failing check, so that every problem of the request is reported at once.
+func (s ServerHandler) ValidateTransferTask(ctx context.Context, request ValidateTransferTaskRequestObject) (ValidateTransferTaskResponseObject, error) {
+	ginCtx, ok := ctx.(*gin.Context)
+	if !ok {
+		return ValidateTransferTask500JSONResponse{
+			Message: getPointerOrNil("context error"),
+		}, nil
+	}
+
+	u, ok := ginCtx.Get("scicatUser")
+	if !ok {
+		return ValidateTransferTask500JSONResponse{
+			Message: getPointerOrNil("no user was found"),
+		}, nil
+	}
+
+	// fetch scicat user
+	scicatUser, ok := u.(User)
+	if !ok {
+		return ValidateTransferTask500JSONResponse{
+			Message: getPointerOrNil("invalid user in context"),
+			Details: getPointerOrNil(fmt.Sprintf("type found: '%s'", reflect.TypeOf(u))),
+		}, nil
+	}
+
+	body := PostTransferTaskJSONBody{}
+	if request.Body != nil {
+		body = PostTransferTaskJSONBody(*request.Body)
+	}
+	transfer, failed, unlock := s.checkTransferRequest(scicatUser, PostTransferTaskParams(request.Params), body, true)
+	defer unlock()
+
+	result := ValidateTransferTask200JSONResponse{
+		SourceCollectionId: getPointerOrNil(transfer.sourceCollectionID),
+		DestCollectionId:   getPointerOrNil(transfer.destCollectionID),
+		SourcePath:         getPointerOrNil(transfer.sourcePath),
+		DestPath:           getPointerOrNil(transfer.destPath),
+		FailedChecks:       make([]ValidationCheck, len(failed)),
+	}
+	if transfer.idempotentJob != nil {
+		result.JobId = &transfer.idempotentJob.ID
+	}
+	for i, check := range failed {
+		result.FailedChecks[i] = ValidationCheck{
+			Check:   check.check,
+			Message: check.message,
+			Details: getPointerOrNil(check.details),
+		}
+	}
+	result.Valid = len(result.FailedChecks) == 0
+	return result, nil
+}
@@ -16,13 +16,103 @@ type Config struct {
 	FacilitySrcGroupTemplate string            `yaml:"facilitySrcGroupTemplate"`
 	FacilityDstGroupTemplate string            `yaml:"facilityDstGroupTemplate"`
 	DstPathTemplate          string            `yaml:"destinationPathTemplate"`
-	Task                     struct {
+	RetrievalPathTemplate    string            `yaml:"retrievalPathTemplate"`
+	TransferOptions          []TransferOptions `yaml:"transferOptions"`
+	StateFile                string            `yaml:"stateFile"`
+	ReconciliationReportFile string            `yaml:"reconciliationReportFile"`
+	// the amount of seconds the service waits for ongoing requests and transfer tasks when shutting down
+	ShutdownTimeout  uint             `yaml:"shutdownTimeout"`
+	SubmissionLimits SubmissionLimits `yaml:"submissionLimits"`
+	Priorities       Priorities       `yaml:"priorities"`
+	FairShare        FairShare        `yaml:"fairShare"`
+	Quotas           Quotas           `yaml:"quotas"`
+	Task             struct {
 		MaxConcurrency int  `yaml:"maxConcurrency"`
 		QueueSize      int  `yaml:"queueSize"`
 		PollInterval   uint `yaml:"pollInterval"`
+		// the amount of seconds a globus task can be inactive or paused before its transfer fails
+		StalledGracePeriod uint `yaml:"stalledGracePeriod"`
+		// the number of consecutive transient errors while tracking a transfer after which it fails
+		MaxConsecutiveErrors int `yaml:"maxConsecutiveErrors"`
 	} `yaml:"task"`
 }
 
+// TransferOptions are the defaults and the allowed values of the globus transfer options of the transfers
+// between a pair of facilities, where an empty facility matches any facility
+type TransferOptions struct {
+	SourceFacility string                 `yaml:"sourceFacility"`
+	DestFacility   string                 `yaml:"destFacility"`
+	Defaults       TransferOptionDefaults `yaml:"defaults"`
+	Allowed        AllowedTransferOptions `yaml:"allowed"`
+	// the maximum amount of seconds a transfer can take before it's cancelled, 0 is unlimited
+	MaxDuration uint `yaml:"maxDuration"`
+}
+
+// SubmissionLimits are the maximum numbers of globus tasks of the service that can be active at once, transfers
+// wait in a queue until their tasks can be submitted within them. 0 is unlimited.
+type SubmissionLimits struct {
+	MaxActiveTasks uint `yaml:"maxActiveTasks"`
+	// the limits of the transfers between pairs of facilities, where the first pair matching a transfer is used and
+	// an empty facility matches any facility. The tasks are counted for each pair of facilities separately.
+	FacilityPairs []FacilityPairLimit `yaml:"facilityPairs"`
+}
+
+// Priorities restrict who can request the priorities of transfers, and how the priority of queued transfers rises
+// so that they aren't starved by transfers of higher priority
+type Priorities struct {
+	// the templates of the groups allowed to request each priority, executed with the source and the destination
+	// facility of the transfer. The priorities that aren't listed can be requested by anyone.
+	GroupTemplates map[string]string `yaml:"groupTemplates"`
+	// the amount of seconds after which the priority of a queued transfer is raised by one level
+	AgingInterval uint `yaml:"agingInterval"`
+}
+
+// FairShare weighs how the queue is shared between the requesters of transfers. The share of a group is divided
+// equally between its users.
+type FairShare struct {
+	// the weights of the owner groups, 1 for the groups that aren't listed
+	GroupWeights map[string]uint `yaml:"groupWeights"`
+}
+
+// Quotas limit the transfers that users and owner groups can request, 0 is unlimited
+type Quotas struct {
+	// the transfers of a user that are queued or ongoing
+	MaxActiveTransfersPerUser uint `yaml:"maxActiveTransfersPerUser"`
+	// the transfers of an owner group that wait in the queue
+	MaxQueuedTransfersPerGroup uint `yaml:"maxQueuedTransfersPerGroup"`
+	// the bytes of the transfers of an owner group requested in the last day and the last 30 days
+	MaxDailyBytesPerGroup   uint64 `yaml:"maxDailyBytesPerGroup"`
+	MaxMonthlyBytesPerGroup uint64 `yaml:"maxMonthlyBytesPerGroup"`
+}
+
+type FacilityPairLimit struct {
+	SourceFacility string `yaml:"sourceFacility"`
+	DestFacility   string `yaml:"destFacility"`
+	MaxActiveTasks uint   `yaml:"maxActiveTasks"`
+}
+
+type TransferOptionDefaults struct {
+	SyncLevel              *string `yaml:"syncLevel"`
+	VerifyChecksum         *bool   `yaml:"verifyChecksum"`
+	EncryptData            *bool   `yaml:"encryptData"`
+	PreserveTimestamp      *bool   `yaml:"preserveTimestamp"`
+	SkipSourceErrors       *bool   `yaml:"skipSourceErrors"`
+	FailOnQuotaErrors      *bool   `yaml:"failOnQuotaErrors"`
+	DeleteDestinationExtra *bool   `yaml:"deleteDestinationExtra"`
+	Label                  *string `yaml:"label"`
+}
+
+// AllowedTransferOptions lists the values each option can take, any value is allowed for the options without a list
+type AllowedTransferOptions struct {
+	SyncLevel              []string `yaml:"syncLevel"`
+	VerifyChecksum         []bool   `yaml:"verifyChecksum"`
+	EncryptData            []bool   `yaml:"encryptData"`
+	PreserveTimestamp      []bool   `yaml:"preserveTimestamp"`
+	SkipSourceErrors       []bool   `yaml:"skipSourceErrors"`
+	FailOnQuotaErrors      []bool   `yaml:"failOnQuotaErrors"`
+	DeleteDestinationExtra []bool   `yaml:"deleteDestinationExtra"`
+}
+
 const confFileName string = "globus-transfer-service-conf.yaml"
 
 func ReadConfig() (Config, error) {
@@ -0,0 +1,157 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "globus_transfer_service"
+
+// Metrics holds the prometheus collectors of the service. They are registered on a registry of their own
+// instead of the global one, so that every instance can be scraped independently through its Handler.
+type Metrics struct {
+	registry                *prometheus.Registry
+	transferRequests        *prometheus.CounterVec
+	bytesTransferred        *prometheus.CounterVec
+	filesTransferred        *prometheus.CounterVec
+	externalRequestDuration *prometheus.HistogramVec
+	externalRequestErrors   *prometheus.CounterVec
+	tokenRefreshes          *prometheus.CounterVec
+}
+
+// PoolStats is the part of the task pool that the pool gauges are read from
+type PoolStats interface {
+	RunningWorkers() int64
+	WaitingTasks() uint64
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		transferRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transfer_requests_total",
+			Help:      "Number of transfer requests, by response status code and facility pair.",
+		}, []string{"code", "source_facility", "destination_facility"}),
+		bytesTransferred: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transferred_bytes_total",
+			Help:      "Number of bytes transferred by the tracked Globus tasks, by facility pair.",
+		}, []string{"source_facility", "destination_facility"}),
+		filesTransferred: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transferred_files_total",
+			Help:      "Number of files transferred by the tracked Globus tasks, by facility pair.",
+		}, []string{"source_facility", "destination_facility"}),
+		externalRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "external_request_duration_seconds",
+			Help:      "Latency of the requests sent to Globus and SciCat, by service and method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"service", "method"}),
+		externalRequestErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "external_request_errors_total",
+			Help:      "Number of requests sent to Globus and SciCat that failed, by service, method and status code ('error' if no response was received).",
+		}, []string{"service", "method", "code"}),
+		tokenRefreshes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "service_user_token_refreshes_total",
+			Help:      "Number of times the token of the SciCat service user was refreshed, by result.",
+		}, []string{"result"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.transferRequests,
+		m.bytesTransferred,
+		m.filesTransferred,
+		m.externalRequestDuration,
+		m.externalRequestErrors,
+		m.tokenRefreshes,
+	)
+	return m
+}
+
+// Handler serves the metrics in the prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObservePool registers the gauges of the running and waiting polls of the workers of the pool
+func (m *Metrics) ObservePool(pool PoolStats) {
+	m.registry.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "pool_running_tasks",
+			Help:      "Number of polls of tracked transfers currently being processed by the workers of the task pool.",
+		}, func() float64 { return float64(pool.RunningWorkers()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "pool_waiting_tasks",
+			Help:      "Number of polls of tracked transfers waiting for a worker of the task pool.",
+		}, func() float64 { return float64(pool.WaitingTasks()) }),
+	)
+}
+
+// ObserveTrackedTransfers registers the gauge of the number of jobs tracked by the pool
+func (m *Metrics) ObserveTrackedTransfers(count func() int) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "pool_tracked_transfers",
+		Help:      "Number of transfer jobs tracked by the task pool.",
+	}, func() float64 { return float64(count()) }))
+}
+
+func (m *Metrics) ObserveTransferRequest(statusCode int, sourceFacility string, destFacility string) {
+	m.transferRequests.WithLabelValues(strconv.Itoa(statusCode), sourceFacility, destFacility).Inc()
+}
+
+// ObserveTransferProgress adds the bytes and files transferred since the last observation of a transfer
+func (m *Metrics) ObserveTransferProgress(sourceFacility string, destFacility string, bytes uint, files uint) {
+	m.bytesTransferred.WithLabelValues(sourceFacility, destFacility).Add(float64(bytes))
+	m.filesTransferred.WithLabelValues(sourceFacility, destFacility).Add(float64(files))
+}
+
+func (m *Metrics) ObserveTokenRefresh(err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	m.tokenRefreshes.WithLabelValues(result).Inc()
+}
+
+// InstrumentTransport wraps the transport of an http client so that the latency and the errors
+// of its requests are recorded under the given service name
+func (m *Metrics) InstrumentTransport(service string, next http.RoundTripper) http.RoundTripper {
+	return instrumentedTransport{
+		metrics: m,
+		service: service,
+		next:    next,
+	}
+}
+
+type instrumentedTransport struct {
+	metrics *Metrics
+	service string
+	next    http.RoundTripper
+}
+
+func (t instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	t.metrics.externalRequestDuration.WithLabelValues(t.service, req.Method).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		t.metrics.externalRequestErrors.WithLabelValues(t.service, req.Method, "error").Inc()
+	} else if resp.StatusCode >= 400 {
+		t.metrics.externalRequestErrors.WithLabelValues(t.service, req.Method, strconv.Itoa(resp.StatusCode)).Inc()
+	}
+	return resp, err
+}
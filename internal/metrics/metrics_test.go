@@ -0,0 +1,180 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SwissOpenEM/globus-transfer-service/internal/api"
+	"github.com/SwissOpenEM/globus-transfer-service/internal/metrics"
+	"github.com/gin-gonic/gin"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+type poolStats struct {
+	running int64
+	waiting uint64
+}
+
+func (p poolStats) RunningWorkers() int64 { return p.running }
+func (p poolStats) WaitingTasks() uint64  { return p.waiting }
+
+// a router with the metrics endpoint and the transfer request middleware like the one of the server, where the
+// requests without a token are rejected like by the authentication middleware
+func newRouter(m *metrics.Metrics) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/metrics", gin.WrapH(m.Handler()))
+	r.Use(
+		api.TransferRequestMetricsMiddleware(m, map[string]string{"SRC": "src-collection", "DST": "dst-collection"}),
+		func(c *gin.Context) {
+			if c.GetHeader("SciCat-API-Key") == "" {
+				c.AbortWithStatus(http.StatusUnauthorized)
+			}
+		},
+	)
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.POST("/transfer", ok)
+	r.POST("/transfer/batch", ok)
+	r.POST("/transfer/retrieval", ok)
+	return r
+}
+
+func request(t *testing.T, r http.Handler, method string, target string, token string) {
+	t.Helper()
+	req := httptest.NewRequest(method, target, nil)
+	if token != "" {
+		req.Header.Set("SciCat-API-Key", token)
+	}
+	r.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+// scrapes the metrics endpoint of the router and parses the exposed metric families
+func scrape(t *testing.T, r http.Handler) map[string]*dto.MetricFamily {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("scraping the metrics returned %d", rec.Code)
+	}
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(rec.Body)
+	if err != nil {
+		t.Fatalf("can't parse the scraped metrics: %s", err.Error())
+	}
+	return families
+}
+
+// the value of the series of a metric with the given labels, or false if there's no such series
+func value(families map[string]*dto.MetricFamily, name string, labels map[string]string) (float64, bool) {
+	family, ok := families[name]
+	if !ok {
+		return 0, false
+	}
+	for _, metric := range family.GetMetric() {
+		matches := 0
+		for _, label := range metric.GetLabel() {
+			if expected, ok := labels[label.GetName()]; ok && expected == label.GetValue() {
+				matches++
+			}
+		}
+		if matches != len(labels) {
+			continue
+		}
+		switch {
+		case metric.Counter != nil:
+			return metric.GetCounter().GetValue(), true
+		case metric.Gauge != nil:
+			return metric.GetGauge().GetValue(), true
+		case metric.Histogram != nil:
+			return float64(metric.GetHistogram().GetSampleCount()), true
+		}
+	}
+	return 0, false
+}
+
+func assertValue(t *testing.T, families map[string]*dto.MetricFamily, name string, labels map[string]string, expected float64) {
+	t.Helper()
+	actual, ok := value(families, name, labels)
+	if !ok {
+		t.Errorf("no series of '%s' with labels %v", name, labels)
+		return
+	}
+	if actual != expected {
+		t.Errorf("'%s' with labels %v is %v, expected %v", name, labels, actual, expected)
+	}
+}
+
+func TestTransferRequests(t *testing.T) {
+	m := metrics.New()
+	r := newRouter(m)
+
+	request(t, r, http.MethodPost, "/transfer?sourceFacility=SRC&destFacility=DST&scicatPid=pid", "token")
+	request(t, r, http.MethodPost, "/transfer?sourceFacility=SRC&destFacility=DST&scicatPid=pid", "token")
+	request(t, r, http.MethodPost, "/transfer?sourceFacility=SRC&destFacility=DST&scicatPid=pid", "")
+	request(t, r, http.MethodPost, "/transfer/batch?sourceFacility=SRC&destFacility=DST", "token")
+	request(t, r, http.MethodPost, "/transfer?sourceFacility=SOMEWHERE&destFacility=DST&scicatPid=pid", "token")
+	request(t, r, http.MethodPost, "/transfer/retrieval?sourceFacility=DST&destFacility=SRC&scicatPid=pid", "token")
+
+	families := scrape(t, r)
+	const name = "globus_transfer_service_transfer_requests_total"
+	assertValue(t, families, name, map[string]string{"code": "200", "source_facility": "SRC", "destination_facility": "DST"}, 3)
+	assertValue(t, families, name, map[string]string{"code": "401", "source_facility": "SRC", "destination_facility": "DST"}, 1)
+	assertValue(t, families, name, map[string]string{"code": "200", "source_facility": "unknown", "destination_facility": "DST"}, 1)
+	if _, ok := value(families, name, map[string]string{"source_facility": "DST", "destination_facility": "SRC"}); ok {
+		t.Errorf("retrievals shouldn't be counted as transfer requests")
+	}
+}
+
+func TestPolling(t *testing.T) {
+	m := metrics.New()
+	m.ObservePool(poolStats{running: 3, waiting: 7})
+	tracked := 5
+	m.ObserveTrackedTransfers(func() int { return tracked })
+	m.ObserveTransferProgress("SRC", "DST", 1024, 2)
+	m.ObserveTransferProgress("SRC", "DST", 512, 1)
+
+	r := newRouter(m)
+	families := scrape(t, r)
+	assertValue(t, families, "globus_transfer_service_pool_running_tasks", nil, 3)
+	assertValue(t, families, "globus_transfer_service_pool_waiting_tasks", nil, 7)
+	assertValue(t, families, "globus_transfer_service_pool_tracked_transfers", nil, 5)
+	assertValue(t, families, "globus_transfer_service_transferred_bytes_total", map[string]string{"source_facility": "SRC", "destination_facility": "DST"}, 1536)
+	assertValue(t, families, "globus_transfer_service_transferred_files_total", map[string]string{"source_facility": "SRC", "destination_facility": "DST"}, 3)
+
+	// the gauges are read at every scrape
+	tracked = 2
+	families = scrape(t, r)
+	assertValue(t, families, "globus_transfer_service_pool_tracked_transfers", nil, 2)
+}
+
+func TestOutboundRequests(t *testing.T) {
+	m := metrics.New()
+	globus := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/unavailable" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer globus.Close()
+	client := &http.Client{Transport: m.InstrumentTransport("globus", http.DefaultTransport)}
+
+	for _, path := range []string{"/task", "/task", "/unavailable"} {
+		resp, err := client.Get(globus.URL + path)
+		if err != nil {
+			t.Fatalf("request to the test server failed: %s", err.Error())
+		}
+		resp.Body.Close()
+	}
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+	if _, err := client.Post(unreachable.URL, "application/json", nil); err == nil {
+		t.Fatalf("request to a closed server should fail")
+	}
+
+	families := scrape(t, newRouter(m))
+	assertValue(t, families, "globus_transfer_service_external_request_duration_seconds", map[string]string{"service": "globus", "method": "GET"}, 3)
+	assertValue(t, families, "globus_transfer_service_external_request_duration_seconds", map[string]string{"service": "globus", "method": "POST"}, 1)
+	assertValue(t, families, "globus_transfer_service_external_request_errors_total", map[string]string{"service": "globus", "method": "GET", "code": "503"}, 1)
+	assertValue(t, families, "globus_transfer_service_external_request_errors_total", map[string]string{"service": "globus", "method": "POST", "code": "error"}, 1)
+}
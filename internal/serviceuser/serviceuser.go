@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/SwissOpenEM/globus-transfer-service/internal/metrics"
 	"github.com/paulscherrerinstitute/scicat-cli/v3/datasetUtils"
 )
 
@@ -17,9 +18,10 @@ type ScicatServiceUser struct {
 	scicatToken *string
 	expiry      *time.Time
 	mutex       *sync.Mutex
+	metrics     *metrics.Metrics
 }
 
-func CreateServiceUser(scicatUrl string, username string, password string) (ScicatServiceUser, error) {
+func CreateServiceUser(scicatUrl string, username string, password string, m *metrics.Metrics) (ScicatServiceUser, error) {
 	var emptyString = ""
 	var zeroTime = time.Time{}
 	var mutex sync.Mutex
@@ -30,6 +32,7 @@ func CreateServiceUser(scicatUrl string, username string, password string) (Scic
 		scicatToken: &emptyString,
 		expiry:      &zeroTime,
 		mutex:       &mutex,
+		metrics:     m,
 	}
 	return serviceUser, serviceUser.refreshToken()
 }
@@ -47,6 +50,12 @@ func (su *ScicatServiceUser) GetToken() (string, error) {
 }
 
 func (su *ScicatServiceUser) refreshToken() error {
+	err := su.login()
+	su.metrics.ObserveTokenRefresh(err)
+	return err
+}
+
+func (su *ScicatServiceUser) login() error {
 	user, _, err := datasetUtils.AuthenticateUser(http.DefaultClient, *su.scicatUrl+"api/v3", *su.username, *su.password, false)
 	if err != nil {
 		return err
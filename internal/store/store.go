@@ -0,0 +1,335 @@
+package store
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"github.com/SwissOpenEM/globus"
+	"github.com/SwissOpenEM/globus-transfer-service/jobs"
+	"go.etcd.io/bbolt"
+)
+
+var (
+	transfersBucket   = []byte("transfers")
+	submissionsBucket = []byte("submissions")
+	usageBucket       = []byte("usage")
+)
+
+// the usage of the quotas of a group is recorded by the hour, in a bucket of the group keyed by the start of the hour
+const usageKeyFormat = "2006-01-02T15"
+
+// Store persists the transfers submitted by the service in a local bbolt database, so that they can be
+// tracked and queried without SciCat, for instance while it's slow or unavailable
+type Store struct {
+	db *bbolt.DB
+}
+
+// Transfer is the local record of a transfer job, keyed by its scicat job id
+type Transfer struct {
+	ScicatJobId  string   `json:"scicatJobId"`
+	OwnerUser    string   `json:"ownerUser"`
+	OwnerGroup   string   `json:"ownerGroup"`
+	AccessGroups []string `json:"accessGroups,omitempty"`
+	// the datasets and their paths, the facilities and the options of the transfer
+	JobParams jobs.JobParams `json:"jobParams"`
+	// the last known status of the transfer, including the globus task of each dataset
+	Status    jobs.JobResultObject `json:"status"`
+	CreatedAt time.Time            `json:"createdAt"`
+	UpdatedAt time.Time            `json:"updatedAt"`
+}
+
+type SubmissionState string
+
+const (
+	// the submission was recorded, but its scicat job might not exist yet
+	SubmissionPending SubmissionState = "pending"
+	// the scicat job exists and waits in the queue until its globus tasks can be submitted
+	SubmissionQueued SubmissionState = "queued"
+	// the scicat job left the queue, and its globus tasks are being submitted
+	SubmissionSubmitting SubmissionState = "submitting"
+	// the submission failed, and its globus tasks and scicat job are being removed
+	SubmissionRollingBack SubmissionState = "rolling back"
+)
+
+// Submission is the record of a transfer job that is being submitted, which is kept until the job is
+// confirmed or rolled back so that submissions interrupted by a crash can be recovered, keyed by its id
+type Submission struct {
+	ID          string          `json:"id"`
+	State       SubmissionState `json:"state"`
+	OwnerUser   string          `json:"ownerUser"`
+	OwnerGroup  string          `json:"ownerGroup"`
+	JobParams   jobs.JobParams  `json:"jobParams"`
+	ScicatJobId string          `json:"scicatJobId,omitempty"`
+	// the globus transfer of each dataset of the job and its task, in the order of its dataset list. The
+	// transfers are submitted once the job leaves the queue, the tasks are empty until then.
+	Transfers     []globus.Transfer `json:"transfers,omitempty"`
+	GlobusTaskIds []string          `json:"globusTaskIds"`
+	// only set for the retry of some datasets of an existing job: the state of the transfer of each dataset of the
+	// job before the retry, and the position in the dataset list of the dataset of each transfer
+	PreviousDatasets []jobs.DatasetTransfer `json:"previousDatasets,omitempty"`
+	RetriedDatasets  []int                  `json:"retriedDatasets,omitempty"`
+	// the number of consecutive submissions of the transfers that failed with a transient error, and the time
+	// before which they aren't submitted again
+	FailedAttempts int       `json:"failedAttempts,omitempty"`
+	NotBefore      time.Time `json:"notBefore,omitempty"`
+	// the deadline of the transfers, set when they're submitted
+	Deadline *time.Time `json:"deadline,omitempty"`
+	// also the position of the job in the queue
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// IsRetry returns whether the submission transfers some datasets of an existing job again
+func (s Submission) IsRetry() bool {
+	return s.PreviousDatasets != nil
+}
+
+// DatasetIndex returns the position in the dataset list of the job of the dataset of the given transfer
+func (s Submission) DatasetIndex(transferIndex int) int {
+	if s.IsRetry() {
+		return s.RetriedDatasets[transferIndex]
+	}
+	return transferIndex
+}
+
+// Open opens the database at the given path, creating it if it doesn't exist yet
+func Open(path string) (*Store, error) {
+	db, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, bucket := range [][]byte{transfersBucket, submissionsBucket, usageBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// NewTransfer creates the record of a transfer from its scicat job
+func NewTransfer(job jobs.ScicatJob) Transfer {
+	return Transfer{
+		ScicatJobId:  job.ID,
+		OwnerUser:    job.OwnerUser,
+		OwnerGroup:   job.OwnerGroup,
+		AccessGroups: job.AccessGroups,
+		JobParams:    job.JobParams,
+		Status:       job.JobResultObject,
+		CreatedAt:    job.CreatedAt,
+	}
+}
+
+// Job returns the scicat job that the transfer is tracked by, as far as it's known locally
+func (t Transfer) Job() jobs.ScicatJob {
+	return jobs.ScicatJob{
+		ID:              t.ScicatJobId,
+		Type:            jobs.GlobusTransferJobType,
+		OwnerUser:       t.OwnerUser,
+		OwnerGroup:      t.OwnerGroup,
+		AccessGroups:    t.AccessGroups,
+		JobParams:       t.JobParams,
+		JobResultObject: t.Status,
+		CreatedAt:       t.CreatedAt,
+		UpdatedAt:       t.UpdatedAt,
+	}
+}
+
+// Attempts returns how many times the datasets of the transfer were submitted to globus, which is
+// the number of attempts of the dataset that was retried the most
+func (t Transfer) Attempts() int {
+	attempts := 1 + len(t.Status.PreviousGlobusTaskIds)
+	for _, dataset := range t.Status.Datasets {
+		attempts = max(attempts, 1+len(dataset.PreviousGlobusTaskIds))
+	}
+	return attempts
+}
+
+// PutTransfer creates or replaces the record of a transfer
+func (s *Store) PutTransfer(transfer Transfer) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return putTransfer(tx.Bucket(transfersBucket), transfer)
+	})
+}
+
+// UpdateTransferStatus replaces the status of a transfer, if it's recorded
+func (s *Store) UpdateTransferStatus(scicatJobId string, status jobs.JobResultObject) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(transfersBucket)
+		transfer, ok, err := getTransfer(bucket, scicatJobId)
+		if err != nil || !ok {
+			return err
+		}
+		transfer.Status = status
+		return putTransfer(bucket, transfer)
+	})
+}
+
+func (s *Store) GetTransfer(scicatJobId string) (Transfer, bool, error) {
+	var transfer Transfer
+	var ok bool
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		var err error
+		transfer, ok, err = getTransfer(tx.Bucket(transfersBucket), scicatJobId)
+		return err
+	})
+	return transfer, ok, err
+}
+
+// ListTransfers returns all the recorded transfers, ordered by scicat job id
+func (s *Store) ListTransfers() ([]Transfer, error) {
+	transfers := []Transfer{}
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(transfersBucket).ForEach(func(_, value []byte) error {
+			var transfer Transfer
+			if err := json.Unmarshal(value, &transfer); err != nil {
+				return err
+			}
+			transfers = append(transfers, transfer)
+			return nil
+		})
+	})
+	return transfers, err
+}
+
+func (s *Store) DeleteTransfer(scicatJobId string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(transfersBucket).Delete([]byte(scicatJobId))
+	})
+}
+
+func getTransfer(bucket *bbolt.Bucket, scicatJobId string) (Transfer, bool, error) {
+	value := bucket.Get([]byte(scicatJobId))
+	if value == nil {
+		return Transfer{}, false, nil
+	}
+	var transfer Transfer
+	err := json.Unmarshal(value, &transfer)
+	return transfer, err == nil, err
+}
+
+func putTransfer(bucket *bbolt.Bucket, transfer Transfer) error {
+	transfer.UpdatedAt = time.Now()
+	value, err := json.Marshal(transfer)
+	if err != nil {
+		return err
+	}
+	return bucket.Put([]byte(transfer.ScicatJobId), value)
+}
+
+func (s *Store) PutSubmission(submission Submission) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		value, err := json.Marshal(submission)
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(submissionsBucket).Put([]byte(submission.ID), value)
+	})
+}
+
+// ListSubmissions returns the submissions that were neither confirmed nor rolled back yet
+func (s *Store) ListSubmissions() ([]Submission, error) {
+	submissions := []Submission{}
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(submissionsBucket).ForEach(func(_, value []byte) error {
+			var submission Submission
+			if err := json.Unmarshal(value, &submission); err != nil {
+				return err
+			}
+			submissions = append(submissions, submission)
+			return nil
+		})
+	})
+	return submissions, err
+}
+
+func (s *Store) DeleteSubmission(id string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(submissionsBucket).Delete([]byte(id))
+	})
+}
+
+// AddUsage adds bytes to the usage of the quotas of a group at the given time, negative ones refund them. The usage
+// is kept apart from the transfers, so that deleting a transfer doesn't refund it.
+func (s *Store) AddUsage(group string, at time.Time, amount int64) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.Bucket(usageBucket).CreateBucketIfNotExists([]byte(group))
+		if err != nil {
+			return err
+		}
+		key := usageKey(at)
+		used := decodeUsage(bucket.Get(key)) + amount
+		return bucket.Put(key, binary.BigEndian.AppendUint64(nil, uint64(used)))
+	})
+}
+
+// Usage returns the bytes used by a group since the start of the hour of the given time
+func (s *Store) Usage(group string, since time.Time) (int64, error) {
+	used := int64(0)
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(usageBucket).Bucket([]byte(group))
+		if bucket == nil {
+			return nil
+		}
+		c := bucket.Cursor()
+		for key, value := c.Seek(usageKey(since)); key != nil; key, value = c.Next() {
+			used += decodeUsage(value)
+		}
+		return nil
+	})
+	return max(used, 0), err
+}
+
+// PruneUsage deletes the usage of all groups recorded before the hour of the given time
+func (s *Store) PruneUsage(before time.Time) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		usage := tx.Bucket(usageBucket)
+		groups := [][]byte{}
+		err := usage.ForEachBucket(func(group []byte) error {
+			groups = append(groups, group)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		beforeKey := usageKey(before)
+		for _, group := range groups {
+			bucket := usage.Bucket(group)
+			c := bucket.Cursor()
+			for key, _ := c.First(); key != nil && bytes.Compare(key, beforeKey) < 0; key, _ = c.First() {
+				if err := c.Delete(); err != nil {
+					return err
+				}
+			}
+			if key, _ := c.First(); key == nil {
+				if err := usage.DeleteBucket(group); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func usageKey(at time.Time) []byte {
+	return []byte(at.UTC().Format(usageKeyFormat))
+}
+
+func decodeUsage(value []byte) int64 {
+	if len(value) != 8 {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(value))
+}
@@ -0,0 +1,178 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.etcd.io/bbolt"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("can't open the store: %s", err.Error())
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func addUsage(t *testing.T, s *Store, group string, at time.Time, amount int64) {
+	t.Helper()
+	if err := s.AddUsage(group, at, amount); err != nil {
+		t.Fatalf("can't add usage: %s", err.Error())
+	}
+}
+
+func usage(t *testing.T, s *Store, group string, since time.Time) int64 {
+	t.Helper()
+	used, err := s.Usage(group, since)
+	if err != nil {
+		t.Fatalf("can't read usage: %s", err.Error())
+	}
+	return used
+}
+
+// the keys of the usage of a group and the bytes recorded under them, nil if the group has no bucket
+func usageRecords(t *testing.T, s *Store, group string) map[string]int64 {
+	t.Helper()
+	var records map[string]int64
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(usageBucket).Bucket([]byte(group))
+		if bucket == nil {
+			return nil
+		}
+		records = map[string]int64{}
+		return bucket.ForEach(func(key []byte, value []byte) error {
+			records[string(key)] = decodeUsage(value)
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("can't read the usage records: %s", err.Error())
+	}
+	return records
+}
+
+func TestUsageHourlyKeys(t *testing.T) {
+	s := openTestStore(t)
+	zurich := time.FixedZone("CEST", 2*60*60)
+
+	addUsage(t, s, "group", time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC), 100)
+	addUsage(t, s, "group", time.Date(2026, 10, 18, 10, 59, 59, 0, time.UTC), 200)
+	addUsage(t, s, "group", time.Date(2026, 10, 18, 12, 30, 0, 0, zurich), 50)
+	addUsage(t, s, "group", time.Date(2026, 10, 18, 11, 0, 0, 0, time.UTC), 7)
+	addUsage(t, s, "other", time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC), 1)
+
+	expected := map[string]int64{"2026-10-18T10": 350, "2026-10-18T11": 7}
+	records := usageRecords(t, s, "group")
+	if len(records) != len(expected) {
+		t.Fatalf("expected the records %v, got %v", expected, records)
+	}
+	for key, used := range expected {
+		if records[key] != used {
+			t.Errorf("expected %d bytes under '%s', got %d", used, key, records[key])
+		}
+	}
+}
+
+func TestUsageWindows(t *testing.T) {
+	s := openTestStore(t)
+	now := time.Date(2026, 10, 18, 12, 30, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	month := 30 * day
+
+	addUsage(t, s, "group", now, 1)
+	addUsage(t, s, "group", now.Add(-day), 10)                 // the hour the day starts in
+	addUsage(t, s, "group", now.Add(-day-time.Hour), 100)      // the hour before the day
+	addUsage(t, s, "group", now.Add(-month), 1000)             // the hour the month starts in
+	addUsage(t, s, "group", now.Add(-month-time.Hour), 10000)  // the hour before the month
+	addUsage(t, s, "group", now.Add(-month-31*time.Minute), 5) // the start of the hour before the month
+
+	tests := []struct {
+		name     string
+		group    string
+		since    time.Time
+		expected int64
+	}{
+		{name: "day", group: "group", since: now.Add(-day), expected: 11},
+		{name: "month", group: "group", since: now.Add(-month), expected: 1111},
+		{name: "start of the hour", group: "group", since: now.Add(-day).Truncate(time.Hour), expected: 11},
+		{name: "end of the hour", group: "group", since: now.Add(-day).Truncate(time.Hour).Add(time.Hour - time.Nanosecond), expected: 11},
+		{name: "next hour", group: "group", since: now.Add(-day).Truncate(time.Hour).Add(time.Hour), expected: 1},
+		{name: "unknown group", group: "unknown", since: now.Add(-month), expected: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if used := usage(t, s, test.group, test.since); used != test.expected {
+				t.Errorf("expected %d bytes, got %d", test.expected, used)
+			}
+		})
+	}
+}
+
+func TestPruneUsage(t *testing.T) {
+	s := openTestStore(t)
+	before := time.Date(2026, 10, 18, 12, 30, 0, 0, time.UTC)
+
+	addUsage(t, s, "group", before.Add(-2*time.Hour), 100)
+	addUsage(t, s, "group", before.Add(-time.Hour), 200)
+	addUsage(t, s, "group", before.Truncate(time.Hour), 300) // kept, as it's in the hour of before
+	addUsage(t, s, "group", before.Add(time.Hour), 400)
+	addUsage(t, s, "old", before.Add(-time.Hour), 500)
+
+	if err := s.PruneUsage(before); err != nil {
+		t.Fatalf("can't prune the usage: %s", err.Error())
+	}
+
+	records := usageRecords(t, s, "group")
+	expected := map[string]int64{"2026-10-18T12": 300, "2026-10-18T13": 400}
+	if len(records) != len(expected) {
+		t.Fatalf("expected the records %v, got %v", expected, records)
+	}
+	for key, used := range expected {
+		if records[key] != used {
+			t.Errorf("expected %d bytes under '%s', got %d", used, key, records[key])
+		}
+	}
+	if records := usageRecords(t, s, "old"); records != nil {
+		t.Errorf("expected the bucket of a group without usage left to be deleted, got %v", records)
+	}
+
+	// pruning again with nothing to prune changes nothing
+	if err := s.PruneUsage(before); err != nil {
+		t.Fatalf("can't prune the usage: %s", err.Error())
+	}
+	if used := usage(t, s, "group", before.Add(-30*24*time.Hour)); used != 700 {
+		t.Errorf("expected 700 bytes, got %d", used)
+	}
+}
+
+func TestRefundUsage(t *testing.T) {
+	s := openTestStore(t)
+	accepted := time.Date(2026, 10, 18, 10, 15, 0, 0, time.UTC)
+	now := accepted.Add(5 * time.Hour)
+
+	addUsage(t, s, "group", accepted, 300)
+	addUsage(t, s, "group", now, 50)
+
+	// a refund is recorded in the hour the usage was charged in, not the one it happens in
+	addUsage(t, s, "group", accepted, -300)
+	if used := usage(t, s, "group", accepted); used != 50 {
+		t.Errorf("expected 50 bytes after the refund, got %d", used)
+	}
+	if used := usage(t, s, "group", now); used != 50 {
+		t.Errorf("expected the refund to leave the current hour untouched, got %d bytes", used)
+	}
+	if records := usageRecords(t, s, "group"); records["2026-10-18T10"] != 0 {
+		t.Errorf("expected nothing left in the hour of the refunded usage, got %d bytes", records["2026-10-18T10"])
+	}
+
+	// refunding more than was used never makes the usage negative
+	addUsage(t, s, "group", now, -500)
+	if used := usage(t, s, "group", accepted); used != 0 {
+		t.Errorf("expected no usage, got %d bytes", used)
+	}
+}
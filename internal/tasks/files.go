@@ -0,0 +1,42 @@
+package tasks
+
+import (
+	"github.com/SwissOpenEM/globus"
+)
+
+// the maximum number of failed files listed per dataset in the result of a scicat job, so that jobs stay small
+const maxReportedFailedFiles = 100
+
+// TransferredFiles returns all the files that a globus task transferred successfully so far
+func TransferredFiles(client globus.GlobusClient, globusTaskId string) ([]globus.SuccessfulTransfer, error) {
+	files := []globus.SuccessfulTransfer{}
+	var marker uint = 0
+	for {
+		page, err := client.TransferGetTaskSuccessfulTransfers(globusTaskId, marker)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, page.Data...)
+		if page.NextMarker == nil {
+			return files, nil
+		}
+		marker = *page.NextMarker
+	}
+}
+
+// SkippedFiles returns all the files that a globus task skipped because of errors so far
+func SkippedFiles(client globus.GlobusClient, globusTaskId string) ([]globus.SkippedError, error) {
+	files := []globus.SkippedError{}
+	var marker uint = 0
+	for {
+		page, err := client.TransferGetTaskSkippedErrors(globusTaskId, marker)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, page.Data...)
+		if page.NextMarker == nil {
+			return files, nil
+		}
+		marker = *page.NextMarker
+	}
+}
@@ -1,12 +1,19 @@
 package tasks
 
 import (
+	"context"
 	"fmt"
+	"log"
+	"reflect"
 	"sync"
 	"time"
 
 	"github.com/SwissOpenEM/globus"
+	"github.com/SwissOpenEM/globus-transfer-service/internal/config"
+	"github.com/SwissOpenEM/globus-transfer-service/internal/metrics"
 	"github.com/SwissOpenEM/globus-transfer-service/internal/serviceuser"
+	"github.com/SwissOpenEM/globus-transfer-service/internal/store"
+	"github.com/SwissOpenEM/globus-transfer-service/jobs"
 	"github.com/alitto/pond/v2"
 )
 
@@ -14,12 +21,47 @@ type TaskPool struct {
 	scicatUrl         string
 	globusClient      globus.GlobusClient
 	scicatServiceUser serviceuser.ScicatServiceUser
-	pool              pond.Pool
-	taskPollInterval  time.Duration
-	cancelTask        map[string]chan struct{}
-	cancelMutex       *sync.Mutex
+	// the workers that process the polls of the tracked transfers
+	pool             pond.Pool
+	maxTracked       int
+	submissionLimits config.SubmissionLimits
+	// the time after which the priority of a queued job is raised by one level
+	priorityAgingInterval time.Duration
+	// the weights of the shares of the queue of the owner groups
+	groupWeights map[string]uint
+	// the accepted jobs waiting for their globus tasks to be submitted
+	queue                *submissionQueue
+	taskPollInterval     time.Duration
+	stalledGracePeriod   time.Duration
+	maxConsecutiveErrors int
+	tracked              map[string]*trackedTransfer
+	trackedMutex         *sync.Mutex
+	taskStatus           map[string]jobs.JobResultObject
+	statusMutex          *sync.Mutex
+	subscribers          map[chan TransferEvent]struct{}
+	subscriberMutex      *sync.Mutex
+	metrics              *metrics.Metrics
+	store                *store.Store
+	// closed when the pool shuts down, when the tracker stopped after that, and when the event streams are closed
+	shutdown         chan struct{}
+	trackerStopped   chan struct{}
+	startOnce        *sync.Once
+	shutdownOnce     *sync.Once
+	eventsClosed     chan struct{}
+	eventsClosedOnce *sync.Once
 }
 
+// TransferEvent is a change in the status of a transfer task held by the pool
+type TransferEvent struct {
+	ScicatJobId string
+	Status      jobs.JobResultObject
+	// whether the job waits in the queue or is being submitted to globus, rather than tracked
+	Queued bool
+}
+
+// the number of events a subscriber can lag behind before being dropped
+const subscriberBufferSize = 64
+
 type JobNotExistError struct {
 	msg string
 }
@@ -28,51 +70,162 @@ func (e *JobNotExistError) Error() string {
 	return e.msg
 }
 
-func CreateTaskPool(scicatUrl string, globusClient globus.GlobusClient, scicatServiceUser serviceuser.ScicatServiceUser, maxConcurrency int, queueSize int, taskPollInterval uint) TaskPool {
-	return TaskPool{
-		scicatUrl:         scicatUrl,
-		globusClient:      globusClient,
-		scicatServiceUser: scicatServiceUser,
-		pool:              pond.NewPool(maxConcurrency, pond.WithQueueSize(queueSize)),
-		taskPollInterval:  time.Duration(taskPollInterval) * time.Second,
-		cancelTask:        map[string]chan struct{}{},
-		cancelMutex:       &sync.Mutex{},
-	}
-}
-
-func (tp TaskPool) AddTransferTask(globusTaskId string, datasetPid string, scicatJobId string) pond.Task {
-	tp.cancelTask[scicatJobId] = make(chan struct{})
-	task := transferTask{
-		scicatUrl:         &tp.scicatUrl,
-		globusClient:      tp.globusClient,
-		scicatServiceUser: tp.scicatServiceUser,
-		globusTaskId:      globusTaskId,
-		datasetPid:        datasetPid,
-		scicatJobId:       scicatJobId,
-		taskPollInterval:  tp.taskPollInterval,
-		cancel:            tp.cancelTask[scicatJobId],
+// Config holds the settings of a task pool
+type Config struct {
+	ScicatUrl string
+	// the number of workers polling the transfers of the tracked jobs
+	MaxConcurrency int
+	// the maximum number of jobs queued and tracked at once, 0 is unlimited
+	QueueSize int
+	// the queued jobs are submitted to globus as long as the active tasks stay within these limits, the ones of
+	// higher priority first
+	SubmissionLimits config.SubmissionLimits
+	// the amount of seconds after which the priority of a queued job is raised by one level
+	PriorityAgingInterval uint
+	// the weights with which jobs of the same priority are shared between their requesters
+	FairShare config.FairShare
+	// the amount of seconds between the polls of the transfers of a job
+	TaskPollInterval uint
+	// the amount of seconds a globus task can be inactive or paused before its transfer fails
+	StalledGracePeriod uint
+	// the number of consecutive transient errors while tracking a transfer after which it fails
+	MaxConsecutiveErrors int
+}
+
+// CreateTaskPool creates the pool with the settings of conf, whose tracker polls the transfers of all tracked jobs
+// once it's started
+func CreateTaskPool(conf Config, globusClient globus.GlobusClient, scicatServiceUser serviceuser.ScicatServiceUser, s *store.Store, m *metrics.Metrics) TaskPool {
+	pool := pond.NewPool(conf.MaxConcurrency)
+	m.ObservePool(pool)
+	tp := TaskPool{
+		scicatUrl:             conf.ScicatUrl,
+		globusClient:          globusClient,
+		scicatServiceUser:     scicatServiceUser,
+		pool:                  pool,
+		maxTracked:            conf.QueueSize,
+		submissionLimits:      conf.SubmissionLimits,
+		priorityAgingInterval: time.Duration(conf.PriorityAgingInterval) * time.Second,
+		groupWeights:          conf.FairShare.GroupWeights,
+		queue:                 newSubmissionQueue(),
+		taskPollInterval:      time.Duration(conf.TaskPollInterval) * time.Second,
+		stalledGracePeriod:    time.Duration(conf.StalledGracePeriod) * time.Second,
+		maxConsecutiveErrors:  conf.MaxConsecutiveErrors,
+		tracked:               map[string]*trackedTransfer{},
+		trackedMutex:          &sync.Mutex{},
+		taskStatus:            map[string]jobs.JobResultObject{},
+		statusMutex:           &sync.Mutex{},
+		subscribers:           map[chan TransferEvent]struct{}{},
+		subscriberMutex:       &sync.Mutex{},
+		metrics:               m,
+		store:                 s,
+		shutdown:              make(chan struct{}),
+		trackerStopped:        make(chan struct{}),
+		startOnce:             &sync.Once{},
+		shutdownOnce:          &sync.Once{},
+		eventsClosed:          make(chan struct{}),
+		eventsClosedOnce:      &sync.Once{},
+	}
+	m.ObserveTrackedTransfers(tp.trackedCount)
+	return tp
+}
+
+// Start starts the tracker, which polls the tracked transfers and submits the queued ones to globus. It's meant to
+// be called once the unfinished jobs are reconciled, so that the tracker doesn't act on jobs that are still being
+// recovered.
+func (tp TaskPool) Start() {
+	tp.startOnce.Do(func() { go tp.track() })
+}
+
+// ResumeTransferTask tracks the transfers of an existing scicat job, where each dataset is transferred by its own
+// globus task. The datasets of retrievals are not marked as archivable once transferred.
+func (tp TaskPool) ResumeTransferTask(job jobs.ScicatJob, datasets []jobs.DatasetTransfer) {
+	tp.addTask(job, datasets, !job.JobParams.Retrieval)
+}
+
+func (tp TaskPool) addTask(job jobs.ScicatJob, datasets []jobs.DatasetTransfer, markFilesReady bool) {
+	tp.submitTask(job, tp.newTransferTask(job, datasets, markFilesReady))
+}
+
+// creates the task that tracks the transfers of a job, without handing it to the tracker
+func (tp TaskPool) newTransferTask(job jobs.ScicatJob, datasets []jobs.DatasetTransfer, markFilesReady bool) *transferTask {
+	scicatJobId := job.ID
+	task := &transferTask{
+		scicatUrl:            &tp.scicatUrl,
+		globusClient:         tp.globusClient,
+		scicatServiceUser:    tp.scicatServiceUser,
+		scicatJobId:          scicatJobId,
+		taskPollInterval:     tp.taskPollInterval,
+		stalledGracePeriod:   tp.stalledGracePeriod,
+		maxConsecutiveErrors: tp.maxConsecutiveErrors,
+		setStatus: func(status jobs.JobResultObject) {
+			tp.setTaskStatus(scicatJobId, status)
+		},
 		cleanup: func() {
-			tp.cancelMutex.Lock()
-			defer tp.cancelMutex.Unlock()
-			delete(tp.cancelTask, scicatJobId)
+			tp.trackedMutex.Lock()
+			delete(tp.tracked, scicatJobId)
+			tp.trackedMutex.Unlock()
+			tp.removeTaskStatus(scicatJobId)
 		},
+		markFilesReady: markFilesReady,
+		metrics:        tp.metrics,
+		priority:       job.JobParams.Priority,
+		ownerUser:      job.OwnerUser,
+		ownerGroup:     job.OwnerGroup,
+		sourceFacility: job.JobParams.SourceFacility,
+		destFacility:   job.JobParams.DestinationFacility,
+		deadline:       job.JobResultObject.Deadline,
+		datasets:       make([]jobs.DatasetTransfer, len(datasets)),
 	}
+	for i, dataset := range datasets {
+		task.datasets[i] = dataset
+		if dataset.Status == "" {
+			task.datasets[i].Status = jobs.Waiting
+		}
+	}
+	return task
+}
 
-	return tp.pool.Submit(task.execute)
+// records the task and its status, and hands it to the tracker which polls it from the next tick on
+func (tp TaskPool) submitTask(job jobs.ScicatJob, task *transferTask) {
+	scicatJobId := job.ID
+
+	status := task.jobResult()
+	status.Status = jobs.Waiting
+	record := store.NewTransfer(job)
+	record.Status = status
+	if err := tp.store.PutTransfer(record); err != nil {
+		log.Printf("'%s' scicat job - can't record the transfer in the local store: %s\n", scicatJobId, err.Error())
+	}
+	tp.setTaskStatus(scicatJobId, status)
+
+	tp.trackedMutex.Lock()
+	tp.tracked[scicatJobId] = &trackedTransfer{task: task, nextPoll: time.Now()}
+	tp.trackedMutex.Unlock()
 }
 
+// CancelTransferTask requests the cancellation of the transfers of a tracked job, which the tracker carries out
+// on its next tick. A job that waits in the queue is cancelled right away.
 func (tp TaskPool) CancelTransferTask(scicatJobId string) error {
-	tp.cancelMutex.Lock()
-	defer tp.cancelMutex.Unlock()
-	if cancelChannel, ok := tp.cancelTask[scicatJobId]; ok {
-		cancelChannel <- struct{}{}
+	tp.trackedMutex.Lock()
+	if tracked, ok := tp.tracked[scicatJobId]; ok {
+		tracked.cancelRequested = true
+		tracked.nextPoll = time.Now()
+		tp.trackedMutex.Unlock()
 		return nil
 	}
+	tp.trackedMutex.Unlock()
+
+	if queued, err := tp.cancelQueued(scicatJobId); queued {
+		return err
+	}
 	return &JobNotExistError{fmt.Sprintf("job with ID '%s' does not exist or is already cancelled/removed", scicatJobId)}
 }
 
 func (tp TaskPool) DeleteTransferTask(scicatJobId string) error {
 	_ = tp.CancelTransferTask(scicatJobId)
+	if err := tp.store.DeleteTransfer(scicatJobId); err != nil {
+		log.Printf("'%s' scicat job - can't delete the transfer from the local store: %s\n", scicatJobId, err.Error())
+	}
 	token, err := tp.scicatServiceUser.GetToken()
 	if err != nil {
 		return err
@@ -80,13 +233,180 @@ func (tp TaskPool) DeleteTransferTask(scicatJobId string) error {
 	return DeleteScicatJob(tp.scicatUrl, token, scicatJobId)
 }
 
+// GetTransferTaskStatus returns the live status of a transfer task that is
+// still tracked by the pool, including the ones that weren't polled yet
+func (tp TaskPool) GetTransferTaskStatus(scicatJobId string) (jobs.JobResultObject, bool) {
+	tp.statusMutex.Lock()
+	defer tp.statusMutex.Unlock()
+	status, ok := tp.taskStatus[scicatJobId]
+	return status, ok
+}
+
+// GetTransferTaskStatuses returns the live status of every transfer task held by the pool, by scicat job id
+func (tp TaskPool) GetTransferTaskStatuses() map[string]jobs.JobResultObject {
+	tp.statusMutex.Lock()
+	defer tp.statusMutex.Unlock()
+	statuses := make(map[string]jobs.JobResultObject, len(tp.taskStatus))
+	for scicatJobId, status := range tp.taskStatus {
+		statuses[scicatJobId] = status
+	}
+	return statuses
+}
+
+func (tp TaskPool) setTaskStatus(scicatJobId string, status jobs.JobResultObject) {
+	tp.statusMutex.Lock()
+	previous, ok := tp.taskStatus[scicatJobId]
+	tp.taskStatus[scicatJobId] = status
+	tp.statusMutex.Unlock()
+
+	if !ok || !reflect.DeepEqual(previous, status) {
+		if err := tp.store.UpdateTransferStatus(scicatJobId, status); err != nil {
+			log.Printf("'%s' scicat job - can't record the status of the transfer in the local store: %s\n", scicatJobId, err.Error())
+		}
+		tp.publish(TransferEvent{ScicatJobId: scicatJobId, Status: status})
+	}
+}
+
+// GetStoredTransferJob returns the transfer job as it was last recorded in the local store, which
+// is used when it can't be fetched from SciCat
+func (tp TaskPool) GetStoredTransferJob(scicatJobId string) (jobs.ScicatJob, bool, error) {
+	transfer, ok, err := tp.store.GetTransfer(scicatJobId)
+	if err != nil || !ok {
+		return jobs.ScicatJob{}, ok, err
+	}
+	return transfer.Job(), true, nil
+}
+
+// removes the status of a task that the pool doesn't hold anymore. If the task stopped without
+// reaching a final status, subscribers get notified that it won't be updated anymore.
+func (tp TaskPool) removeTaskStatus(scicatJobId string) {
+	tp.statusMutex.Lock()
+	status, ok := tp.taskStatus[scicatJobId]
+	delete(tp.taskStatus, scicatJobId)
+	tp.statusMutex.Unlock()
+
+	if ok && !status.Status.IsFinal() {
+		status.Status = jobs.Failed
+		status.Error = "the transfer is not tracked anymore by the service"
+		tp.publish(TransferEvent{ScicatJobId: scicatJobId, Status: status})
+	}
+}
+
+// SubscribeTransferEvents returns a channel receiving the status changes of all transfer tasks held by the pool,
+// and a function to unsubscribe with. The channel is closed when unsubscribing, or when the subscriber lags
+// too far behind the events.
+func (tp TaskPool) SubscribeTransferEvents() (<-chan TransferEvent, func()) {
+	events := make(chan TransferEvent, subscriberBufferSize)
+	tp.subscriberMutex.Lock()
+	select {
+	case <-tp.eventsClosed:
+		close(events)
+		tp.subscriberMutex.Unlock()
+		return events, func() {}
+	default:
+	}
+	tp.subscribers[events] = struct{}{}
+	tp.subscriberMutex.Unlock()
+
+	return events, func() {
+		tp.subscriberMutex.Lock()
+		defer tp.subscriberMutex.Unlock()
+		if _, ok := tp.subscribers[events]; ok {
+			delete(tp.subscribers, events)
+			close(events)
+		}
+	}
+}
+
+func (tp TaskPool) publish(event TransferEvent) {
+	tp.subscriberMutex.Lock()
+	defer tp.subscriberMutex.Unlock()
+	for events := range tp.subscribers {
+		select {
+		case events <- event:
+		default:
+			// the subscriber can't keep up, drop it rather than blocking the tasks
+			delete(tp.subscribers, events)
+			close(events)
+		}
+	}
+}
+
+// CloseTransferEvents closes the channels of all the subscribers, and the ones of later subscribers right away,
+// which ends the event streams so that the server can shut down
+func (tp TaskPool) CloseTransferEvents() {
+	tp.subscriberMutex.Lock()
+	defer tp.subscriberMutex.Unlock()
+	tp.eventsClosedOnce.Do(func() { close(tp.eventsClosed) })
+	for events := range tp.subscribers {
+		delete(tp.subscribers, events)
+		close(events)
+	}
+}
+
+// Shutdown stops tracking the transfers without ending them, so that the reconciliation of the next start of the
+// service resumes them. The tracker stops scheduling polls and the ongoing ones are completed, then the tasks
+// report their last status to scicat if it couldn't be updated. Returns an error if this isn't done before the
+// context is done.
+func (tp TaskPool) Shutdown(ctx context.Context) error {
+	tp.shutdownOnce.Do(func() { close(tp.shutdown) })
+	// a tracker that was never started has nothing to stop, and won't be started anymore
+	tp.startOnce.Do(func() { close(tp.trackerStopped) })
+	select {
+	case <-tp.trackerStopped:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	stopped := tp.pool.Stop()
+	select {
+	case <-stopped.Done():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	tp.trackedMutex.Lock()
+	tasks := make([]*transferTask, 0, len(tp.tracked))
+	for _, tracked := range tp.tracked {
+		tasks = append(tasks, tracked.task)
+	}
+	tp.trackedMutex.Unlock()
+	for _, task := range tasks {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		task.flushStatus()
+	}
+	return nil
+}
+
+func (tp TaskPool) isShuttingDown() bool {
+	select {
+	case <-tp.shutdown:
+		return true
+	default:
+		return false
+	}
+}
+
+// CanSubmitJob returns whether the pool can queue one more job
 func (tp TaskPool) CanSubmitJob() bool {
-	if tp.pool.QueueSize() == 0 {
+	if tp.maxTracked == 0 {
 		return true
 	}
-	return tp.pool.WaitingTasks() < uint64(tp.pool.QueueSize())
+	return tp.trackedCount()+tp.queuedCount() < tp.maxTracked
+}
+
+// IsAcceptingTasks returns whether the pool is running and has room for more tasks
+func (tp TaskPool) IsAcceptingTasks() bool {
+	return !tp.pool.Stopped() && !tp.isShuttingDown() && tp.CanSubmitJob()
 }
 
 func (tp TaskPool) IsQueueSizeLimited() bool {
-	return tp.pool.QueueSize() > 0
+	return tp.maxTracked > 0
+}
+
+func (tp TaskPool) trackedCount() int {
+	tp.trackedMutex.Lock()
+	defer tp.trackedMutex.Unlock()
+	return len(tp.tracked)
 }
@@ -0,0 +1,360 @@
+package tasks
+
+import (
+	"cmp"
+	"log"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/SwissOpenEM/globus-transfer-service/internal/store"
+	"github.com/SwissOpenEM/globus-transfer-service/jobs"
+)
+
+// submissionQueue holds the jobs whose globus tasks wait to be submitted, in the order they were accepted, and the
+// ones whose tasks are being submitted by a worker of the pool
+type submissionQueue struct {
+	mutex      *sync.Mutex
+	queued     []store.Submission
+	submitting map[string]store.Submission
+	// the jobs that were cancelled while their tasks were being submitted, which are cancelled once tracked
+	cancelRequested map[string]bool
+}
+
+func newSubmissionQueue() *submissionQueue {
+	return &submissionQueue{
+		mutex:           &sync.Mutex{},
+		queued:          []store.Submission{},
+		submitting:      map[string]store.Submission{},
+		cancelRequested: map[string]bool{},
+	}
+}
+
+// the facilities a transfer is between, which the active tasks are counted by
+type facilityPair struct {
+	source string
+	dest   string
+}
+
+// the user and the owner group a job was requested by, between which the queue is shared
+type requester struct {
+	user  string
+	group string
+}
+
+// the globus tasks that count towards the limits and the shares of the queue, in total, by facility pair and by
+// requester
+type activeTasks struct {
+	total       int
+	byPair      map[facilityPair]int
+	byRequester map[requester]int
+}
+
+func (a *activeTasks) add(pair facilityPair, requester requester, count int) {
+	a.total += count
+	a.byPair[pair] += count
+	a.byRequester[requester] += count
+}
+
+// adds a submission to the queue, at its position according to when it was accepted
+func (tp TaskPool) enqueue(submission store.Submission) {
+	tp.queue.mutex.Lock()
+	tp.insertQueued(submission)
+	tp.queue.mutex.Unlock()
+	tp.publishQueued(submission, jobs.Waiting, "")
+}
+
+// inserts a submission in the queue at its position according to when it was accepted. Must be called with the
+// queue mutex held.
+func (tp TaskPool) insertQueued(submission store.Submission) {
+	i, _ := slices.BinarySearchFunc(tp.queue.queued, submission, func(queued store.Submission, target store.Submission) int {
+		return queued.CreatedAt.Compare(target.CreatedAt)
+	})
+	tp.queue.queued = slices.Insert(tp.queue.queued, i, submission)
+}
+
+// notifies the subscribers of the status of a job that waits in the queue or is being submitted, or whose
+// submission ended without it being tracked
+func (tp TaskPool) publishQueued(submission store.Submission, status jobs.JobStatus, reason string) {
+	tp.publish(TransferEvent{
+		ScicatJobId: submission.ScicatJobId,
+		Status:      submissionJobResult(submission, status, reason),
+		Queued:      !status.IsFinal(),
+	})
+}
+
+// dispatchQueued hands the jobs at the front of the queue to the workers of the pool for submitting their globus
+// tasks, as long as the active tasks stay within the limits. The queue is served by priority, then by fair share. A job
+// that doesn't fit within the limit of its facility pair holds back the jobs behind it with the same pair. A job
+// that has more datasets than a limit allows is submitted once no other task counts towards that limit. The jobs
+// whose last submission failed with a transient error are skipped until their backoff delay passed.
+func (tp TaskPool) dispatchQueued() {
+	active := tp.activeGlobusTasks()
+
+	tp.queue.mutex.Lock()
+	tp.addSubmittingTasks(&active)
+
+	dispatched := []store.Submission{}
+	blockedPairs := map[facilityPair]bool{}
+	now := time.Now()
+	for _, submission := range tp.dispatchOrder(now, active) {
+		if now.Before(submission.NotBefore) {
+			continue
+		}
+		tasks := len(submission.Transfers)
+		if !withinLimit(tp.submissionLimits.MaxActiveTasks, active.total, tasks) {
+			break
+		}
+		pair := submissionFacilityPair(submission)
+		if blockedPairs[pair] || !withinLimit(tp.facilityPairLimit(pair), active.byPair[pair], tasks) {
+			blockedPairs[pair] = true
+			continue
+		}
+
+		active.add(pair, submissionRequester(submission), tasks)
+		tp.queue.submitting[submission.ScicatJobId] = submission
+		dispatched = append(dispatched, submission)
+	}
+	tp.queue.queued = slices.DeleteFunc(tp.queue.queued, func(submission store.Submission) bool {
+		_, ok := tp.queue.submitting[submission.ScicatJobId]
+		return ok
+	})
+	tp.queue.mutex.Unlock()
+
+	for _, submission := range dispatched {
+		tp.pool.Submit(func() {
+			tp.submitQueued(submission)
+		})
+	}
+}
+
+// the queued jobs in the order they leave the queue, by their priority at the given time then by their fair share.
+// Each requester is served at the rate of its weight: the jobs are ordered by the number of tasks their requester
+// would have been served once they're submitted, including its active tasks, divided by its weight. This
+// interleaves the jobs of the requesters instead of serving the ones that queued many jobs first. Must be called
+// with the queue mutex held.
+func (tp TaskPool) dispatchOrder(now time.Time, active activeTasks) []store.Submission {
+	weights := tp.requesterWeights(active)
+	served := maps.Clone(active.byRequester)
+	finish := make(map[string]float64, len(tp.queue.queued))
+	for _, submission := range tp.queue.queued {
+		requester := submissionRequester(submission)
+		served[requester] += len(submission.Transfers)
+		finish[submission.ScicatJobId] = float64(served[requester]) / weights[requester]
+	}
+
+	ordered := slices.Clone(tp.queue.queued)
+	slices.SortStableFunc(ordered, func(a store.Submission, b store.Submission) int {
+		if levels := tp.queuedPriority(b, now).Level() - tp.queuedPriority(a, now).Level(); levels != 0 {
+			return levels
+		}
+		return cmp.Compare(finish[a.ScicatJobId], finish[b.ScicatJobId])
+	})
+	return ordered
+}
+
+// the weight of each requester with active or queued tasks, which is the weight of its group shared equally between
+// the users of the group. Must be called with the queue mutex held.
+func (tp TaskPool) requesterWeights(active activeTasks) map[requester]float64 {
+	requesters := map[requester]bool{}
+	for requester := range active.byRequester {
+		requesters[requester] = true
+	}
+	for _, submission := range tp.queue.queued {
+		requesters[submissionRequester(submission)] = true
+	}
+
+	usersByGroup := map[string]int{}
+	for requester := range requesters {
+		usersByGroup[requester.group]++
+	}
+	weights := make(map[requester]float64, len(requesters))
+	for requester := range requesters {
+		weights[requester] = tp.groupWeight(requester.group) / float64(usersByGroup[requester.group])
+	}
+	return weights
+}
+
+// the configured weight of a group, 1 if not set
+func (tp TaskPool) groupWeight(group string) float64 {
+	if weight := tp.groupWeights[group]; weight > 0 {
+		return float64(weight)
+	}
+	return 1
+}
+
+// the priority of a queued job, raised by one level for every aging interval it waited, so that jobs of low
+// priority eventually leave the queue however many jobs of higher priority keep coming
+func (tp TaskPool) queuedPriority(submission store.Submission, now time.Time) jobs.Priority {
+	levels := 0
+	if tp.priorityAgingInterval > 0 {
+		levels = int(now.Sub(submission.CreatedAt) / tp.priorityAgingInterval)
+	}
+	return submission.JobParams.Priority.Raised(levels)
+}
+
+// whether a job with the given number of tasks can be submitted while the given number of tasks are active
+func withinLimit(limit uint, active int, tasks int) bool {
+	return limit == 0 || active == 0 || active+tasks <= int(limit)
+}
+
+// counts the globus tasks of the tracked jobs that are still active
+func (tp TaskPool) activeGlobusTasks() activeTasks {
+	tp.trackedMutex.Lock()
+	tasks := make(map[string]*transferTask, len(tp.tracked))
+	for scicatJobId, tracked := range tp.tracked {
+		tasks[scicatJobId] = tracked.task
+	}
+	tp.trackedMutex.Unlock()
+
+	active := activeTasks{byPair: map[facilityPair]int{}, byRequester: map[requester]int{}}
+	for scicatJobId, task := range tasks {
+		status, ok := tp.GetTransferTaskStatus(scicatJobId)
+		if !ok {
+			continue
+		}
+		count := 0
+		for _, dataset := range status.Datasets {
+			if dataset.Status.IsActive() {
+				count++
+			}
+		}
+		pair := facilityPair{source: task.sourceFacility, dest: task.destFacility}
+		active.add(pair, requester{user: task.ownerUser, group: task.ownerGroup}, count)
+	}
+	return active
+}
+
+// counts the globus tasks of the jobs being submitted, which are all about to be active. Must be called with the
+// queue mutex held.
+func (tp TaskPool) addSubmittingTasks(active *activeTasks) {
+	for _, submission := range tp.queue.submitting {
+		active.add(submissionFacilityPair(submission), submissionRequester(submission), len(submission.Transfers))
+	}
+}
+
+// the limit of active tasks of the first facility pair of the limits that matches the pair, 0 if none matches
+func (tp TaskPool) facilityPairLimit(pair facilityPair) uint {
+	for _, limit := range tp.submissionLimits.FacilityPairs {
+		if (limit.SourceFacility == "" || limit.SourceFacility == pair.source) && (limit.DestFacility == "" || limit.DestFacility == pair.dest) {
+			return limit.MaxActiveTasks
+		}
+	}
+	return 0
+}
+
+func submissionFacilityPair(submission store.Submission) facilityPair {
+	return facilityPair{source: submission.JobParams.SourceFacility, dest: submission.JobParams.DestinationFacility}
+}
+
+func submissionRequester(submission store.Submission) requester {
+	return requester{user: submission.OwnerUser, group: submission.OwnerGroup}
+}
+
+// removes a job from the submitting ones once a worker is done submitting it, and cancels it if this was requested
+// in the meantime. A job whose submission is attempted again is put back in the queue at the same time, so that the
+// queue can't dispatch it again before the worker is done with it, nor drop it as being submitted.
+func (tp TaskPool) doneSubmitting(scicatJobId string, requeued *store.Submission) {
+	tp.queue.mutex.Lock()
+	delete(tp.queue.submitting, scicatJobId)
+	if requeued != nil {
+		tp.insertQueued(*requeued)
+	}
+	cancelled := tp.queue.cancelRequested[scicatJobId]
+	delete(tp.queue.cancelRequested, scicatJobId)
+	tp.queue.mutex.Unlock()
+
+	if requeued != nil {
+		tp.publishQueued(*requeued, jobs.Waiting, "")
+	}
+
+	if cancelled {
+		if err := tp.CancelTransferTask(scicatJobId); err != nil {
+			log.Printf("'%s' scicat job - can't cancel the job after submitting it: %s\n", scicatJobId, err.Error())
+		}
+	}
+}
+
+// QueuePosition returns the position of a job in the queue of the jobs waiting to be submitted to globus, starting
+// at 1, and its current priority, or false if the job isn't waiting
+func (tp TaskPool) QueuePosition(scicatJobId string) (int, jobs.Priority, bool) {
+	active := tp.activeGlobusTasks()
+	tp.queue.mutex.Lock()
+	defer tp.queue.mutex.Unlock()
+	tp.addSubmittingTasks(&active)
+	now := time.Now()
+	for i, submission := range tp.dispatchOrder(now, active) {
+		if submission.ScicatJobId == scicatJobId {
+			return i + 1, tp.queuedPriority(submission, now), true
+		}
+	}
+	return 0, "", false
+}
+
+// IsQueued returns whether a job waits to be submitted to globus or is being submitted
+func (tp TaskPool) IsQueued(scicatJobId string) bool {
+	tp.queue.mutex.Lock()
+	defer tp.queue.mutex.Unlock()
+	if _, ok := tp.queue.submitting[scicatJobId]; ok {
+		return true
+	}
+	return slices.ContainsFunc(tp.queue.queued, func(submission store.Submission) bool {
+		return submission.ScicatJobId == scicatJobId
+	})
+}
+
+// cancels a job that waits to be submitted to globus, or requests its cancellation once tracked if it's being
+// submitted. Returns false if the job isn't queued.
+func (tp TaskPool) cancelQueued(scicatJobId string) (bool, error) {
+	tp.queue.mutex.Lock()
+	if _, ok := tp.queue.submitting[scicatJobId]; ok {
+		tp.queue.cancelRequested[scicatJobId] = true
+		tp.queue.mutex.Unlock()
+		return true, nil
+	}
+	i := slices.IndexFunc(tp.queue.queued, func(submission store.Submission) bool {
+		return submission.ScicatJobId == scicatJobId
+	})
+	if i < 0 {
+		tp.queue.mutex.Unlock()
+		return false, nil
+	}
+	submission := tp.queue.queued[i]
+	tp.queue.queued = slices.Delete(tp.queue.queued, i, i+1)
+	tp.queue.mutex.Unlock()
+
+	if err := tp.store.DeleteSubmission(submission.ID); err != nil {
+		log.Printf("'%s' scicat job - can't delete the cancelled submission from the local store: %s\n", scicatJobId, err.Error())
+	}
+	tp.refundUsage(submission)
+	tp.publishQueued(submission, jobs.Cancelled, "")
+	token, err := tp.scicatServiceUser.GetToken()
+	if err != nil {
+		return true, err
+	}
+	_, err = CancelUnsubmittedScicatJob(tp.scicatUrl, token, scicatJobId, submissionJobResult(submission, jobs.Cancelled, ""))
+	return true, err
+}
+
+// GetQueuedTransferStatuses returns the status of every job waiting to be submitted to globus or being submitted, by
+// scicat job id
+func (tp TaskPool) GetQueuedTransferStatuses() map[string]jobs.JobResultObject {
+	tp.queue.mutex.Lock()
+	defer tp.queue.mutex.Unlock()
+	statuses := make(map[string]jobs.JobResultObject, len(tp.queue.queued)+len(tp.queue.submitting))
+	for _, submission := range tp.queue.queued {
+		statuses[submission.ScicatJobId] = submissionJobResult(submission, jobs.Waiting, "")
+	}
+	for scicatJobId, submission := range tp.queue.submitting {
+		statuses[scicatJobId] = submissionJobResult(submission, jobs.Submitting, "")
+	}
+	return statuses
+}
+
+// the number of jobs waiting to be submitted to globus or being submitted
+func (tp TaskPool) queuedCount() int {
+	tp.queue.mutex.Lock()
+	defer tp.queue.mutex.Unlock()
+	return len(tp.queue.queued) + len(tp.queue.submitting)
+}
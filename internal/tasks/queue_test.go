@@ -0,0 +1,174 @@
+package tasks
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/SwissOpenEM/globus"
+	"github.com/SwissOpenEM/globus-transfer-service/internal/config"
+	"github.com/SwissOpenEM/globus-transfer-service/internal/store"
+	"github.com/SwissOpenEM/globus-transfer-service/jobs"
+)
+
+// a queued job of the given requester with one task per dataset, accepted at the given time
+func queuedSubmission(scicatJobId string, user string, group string, priority jobs.Priority, tasks int, createdAt time.Time) store.Submission {
+	return store.Submission{
+		ID:          scicatJobId,
+		State:       store.SubmissionQueued,
+		OwnerUser:   user,
+		OwnerGroup:  group,
+		JobParams:   jobs.JobParams{Priority: priority},
+		ScicatJobId: scicatJobId,
+		Transfers:   make([]globus.Transfer, tasks),
+		CreatedAt:   createdAt,
+	}
+}
+
+func TestDispatchOrder(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	// the jobs are accepted a second apart, in the order they're listed
+	accepted := func(i int) time.Time { return now.Add(-time.Hour).Add(time.Duration(i) * time.Second) }
+
+	tests := []struct {
+		name          string
+		groupWeights  map[string]uint
+		agingInterval time.Duration
+		active        map[requester]int
+		queued        []store.Submission
+		expected      []string
+	}{
+		{
+			name:         "groups with weights 2:1",
+			groupWeights: map[string]uint{"group-a": 2, "group-b": 1},
+			queued: []store.Submission{
+				queuedSubmission("b1", "user-b", "group-b", "", 1, accepted(0)),
+				queuedSubmission("b2", "user-b", "group-b", "", 1, accepted(1)),
+				queuedSubmission("b3", "user-b", "group-b", "", 1, accepted(2)),
+				queuedSubmission("a1", "user-a", "group-a", "", 1, accepted(3)),
+				queuedSubmission("a2", "user-a", "group-a", "", 1, accepted(4)),
+				queuedSubmission("a3", "user-a", "group-a", "", 1, accepted(5)),
+			},
+			expected: []string{"a1", "b1", "a2", "a3", "b2", "b3"},
+		},
+		{
+			name: "two users in one group",
+			queued: []store.Submission{
+				queuedSubmission("u1-1", "user-1", "group", "", 1, accepted(0)),
+				queuedSubmission("u1-2", "user-1", "group", "", 1, accepted(1)),
+				queuedSubmission("u1-3", "user-1", "group", "", 1, accepted(2)),
+				queuedSubmission("u2-1", "user-2", "group", "", 1, accepted(3)),
+				queuedSubmission("u2-2", "user-2", "group", "", 1, accepted(4)),
+			},
+			expected: []string{"u1-1", "u2-1", "u1-2", "u2-2", "u1-3"},
+		},
+		{
+			name:   "active tasks count as served",
+			active: map[requester]int{{user: "user-1", group: "group"}: 2},
+			queued: []store.Submission{
+				queuedSubmission("u1-1", "user-1", "group", "", 1, accepted(0)),
+				queuedSubmission("u2-1", "user-2", "group", "", 1, accepted(1)),
+				queuedSubmission("u2-2", "user-2", "group", "", 1, accepted(2)),
+			},
+			expected: []string{"u2-1", "u2-2", "u1-1"},
+		},
+		{
+			name: "jobs with more tasks are served later",
+			queued: []store.Submission{
+				queuedSubmission("big", "user-1", "group-1", "", 3, accepted(0)),
+				queuedSubmission("small", "user-2", "group-2", "", 1, accepted(1)),
+			},
+			expected: []string{"small", "big"},
+		},
+		{
+			name:          "priority before fair share",
+			agingInterval: 2 * time.Hour,
+			queued: []store.Submission{
+				queuedSubmission("low", "user-1", "group-1", jobs.PriorityLow, 1, accepted(0)),
+				queuedSubmission("normal", "user-1", "group-1", "", 1, accepted(1)),
+				queuedSubmission("high", "user-2", "group-2", jobs.PriorityHigh, 5, accepted(2)),
+			},
+			expected: []string{"high", "normal", "low"},
+		},
+		{
+			name:          "low priority job aging past a high priority one",
+			agingInterval: 20 * time.Minute,
+			queued: []store.Submission{
+				queuedSubmission("low", "user-1", "group-1", jobs.PriorityLow, 1, accepted(0)),
+				queuedSubmission("high", "user-2", "group-2", jobs.PriorityHigh, 1, now.Add(-time.Minute)),
+			},
+			expected: []string{"low", "high"},
+		},
+		{
+			name:          "low priority job not aged enough",
+			agingInterval: 45 * time.Minute,
+			queued: []store.Submission{
+				queuedSubmission("low", "user-1", "group-1", jobs.PriorityLow, 1, accepted(0)),
+				queuedSubmission("high", "user-2", "group-2", jobs.PriorityHigh, 1, now.Add(-time.Minute)),
+			},
+			expected: []string{"high", "low"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tp := TaskPool{
+				groupWeights:          test.groupWeights,
+				priorityAgingInterval: test.agingInterval,
+				queue:                 newSubmissionQueue(),
+			}
+			for _, submission := range test.queued {
+				tp.insertQueued(submission)
+			}
+			active := activeTasks{byPair: map[facilityPair]int{}, byRequester: map[requester]int{}}
+			for requester, count := range test.active {
+				active.add(facilityPair{}, requester, count)
+			}
+
+			ordered := []string{}
+			for _, submission := range tp.dispatchOrder(now, active) {
+				ordered = append(ordered, submission.ScicatJobId)
+			}
+			if !slices.Equal(ordered, test.expected) {
+				t.Errorf("expected the order %v, got %v", test.expected, ordered)
+			}
+		})
+	}
+}
+
+func TestWithinFacilityPairLimit(t *testing.T) {
+	limits := config.SubmissionLimits{
+		FacilityPairs: []config.FacilityPairLimit{
+			{SourceFacility: "SRC", DestFacility: "DST", MaxActiveTasks: 2},
+			{SourceFacility: "SRC", MaxActiveTasks: 5},
+			{DestFacility: "ARCHIVE", MaxActiveTasks: 1},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		limits   config.SubmissionLimits
+		pair     facilityPair
+		active   int
+		tasks    int
+		expected bool
+	}{
+		{name: "no limits", pair: facilityPair{source: "SRC", dest: "DST"}, active: 100, tasks: 10, expected: true},
+		{name: "exact pair within the limit", limits: limits, pair: facilityPair{source: "SRC", dest: "DST"}, active: 1, tasks: 1, expected: true},
+		{name: "exact pair over the limit", limits: limits, pair: facilityPair{source: "SRC", dest: "DST"}, active: 1, tasks: 2, expected: false},
+		{name: "first matching pair is used", limits: limits, pair: facilityPair{source: "SRC", dest: "ARCHIVE"}, active: 3, tasks: 2, expected: true},
+		{name: "any source facility", limits: limits, pair: facilityPair{source: "OTHER", dest: "ARCHIVE"}, active: 1, tasks: 1, expected: false},
+		{name: "no matching pair", limits: limits, pair: facilityPair{source: "OTHER", dest: "DST"}, active: 100, tasks: 10, expected: true},
+		{name: "more tasks than the limit without active ones", limits: limits, pair: facilityPair{source: "SRC", dest: "DST"}, active: 0, tasks: 3, expected: true},
+		{name: "more tasks than the limit with active ones", limits: limits, pair: facilityPair{source: "SRC", dest: "DST"}, active: 1, tasks: 3, expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tp := TaskPool{submissionLimits: test.limits}
+			if fits := withinLimit(tp.facilityPairLimit(test.pair), test.active, test.tasks); fits != test.expected {
+				t.Errorf("expected %t for %d tasks with %d active ones, got %t", test.expected, test.tasks, test.active, fits)
+			}
+		})
+	}
+}
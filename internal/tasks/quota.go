@@ -0,0 +1,104 @@
+package tasks
+
+import (
+	"log"
+	"time"
+
+	"github.com/SwissOpenEM/globus-transfer-service/internal/store"
+)
+
+// the rolling windows over which the bytes of the transfers of a group are counted, to the hour
+const (
+	QuotaDay   = 24 * time.Hour
+	QuotaMonth = 30 * QuotaDay
+)
+
+// GroupUsage is what an owner group currently uses of its quotas
+type GroupUsage struct {
+	QueuedTransfers int
+	DailyBytes      int64
+	MonthlyBytes    int64
+}
+
+// ActiveTransfers counts the transfers of a user that are queued, being submitted or tracked
+func (tp TaskPool) ActiveTransfers(user string) int {
+	count := 0
+	tp.trackedMutex.Lock()
+	for _, tracked := range tp.tracked {
+		if tracked.task.ownerUser == user {
+			count++
+		}
+	}
+	tp.trackedMutex.Unlock()
+
+	tp.queue.mutex.Lock()
+	defer tp.queue.mutex.Unlock()
+	for _, submission := range tp.queue.submitting {
+		if submission.OwnerUser == user {
+			count++
+		}
+	}
+	for _, submission := range tp.queue.queued {
+		if submission.OwnerUser == user {
+			count++
+		}
+	}
+	return count
+}
+
+// GroupUsages returns the usage of the quotas of each of the groups at the given time. The bytes of a transfer are
+// the size of its datasets, which are counted when it's accepted, and refunded if it's never submitted to globus.
+func (tp TaskPool) GroupUsages(groups []string, now time.Time) (map[string]GroupUsage, error) {
+	usages := make(map[string]GroupUsage, len(groups))
+	for _, group := range groups {
+		usages[group] = GroupUsage{}
+	}
+
+	tp.queue.mutex.Lock()
+	for _, submission := range tp.queue.queued {
+		if usage, ok := usages[submission.OwnerGroup]; ok {
+			usage.QueuedTransfers++
+			usages[submission.OwnerGroup] = usage
+		}
+	}
+	tp.queue.mutex.Unlock()
+
+	for group, usage := range usages {
+		var err error
+		if usage.DailyBytes, err = tp.store.Usage(group, now.Add(-QuotaDay)); err != nil {
+			return nil, err
+		}
+		if usage.MonthlyBytes, err = tp.store.Usage(group, now.Add(-QuotaMonth)); err != nil {
+			return nil, err
+		}
+		usages[group] = usage
+	}
+	return usages, nil
+}
+
+// counts the bytes of an accepted submission towards the quotas of its group, and forgets the usage that is too old to
+// count towards any quota
+func (tp TaskPool) chargeUsage(submission store.Submission) {
+	if err := tp.store.AddUsage(submission.OwnerGroup, submission.CreatedAt, requestedBytes(submission)); err != nil {
+		log.Printf("'%s' scicat job - can't record the usage of the quotas in the local store: %s\n", submission.ScicatJobId, err.Error())
+	}
+	if err := tp.store.PruneUsage(time.Now().Add(-QuotaMonth)); err != nil {
+		log.Printf("can't prune the usage of the quotas in the local store: %s\n", err.Error())
+	}
+}
+
+// refunds the bytes of a submission whose transfers were never submitted to globus
+func (tp TaskPool) refundUsage(submission store.Submission) {
+	if err := tp.store.AddUsage(submission.OwnerGroup, submission.CreatedAt, -requestedBytes(submission)); err != nil {
+		log.Printf("'%s' scicat job - can't refund the usage of the quotas in the local store: %s\n", submission.ScicatJobId, err.Error())
+	}
+}
+
+// the size of the datasets a submission transfers
+func requestedBytes(submission store.Submission) int64 {
+	bytes := int64(0)
+	for i := range submission.Transfers {
+		bytes += submission.JobParams.DatasetList[submission.DatasetIndex(i)].Size
+	}
+	return bytes
+}
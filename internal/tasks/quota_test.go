@@ -0,0 +1,84 @@
+package tasks
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SwissOpenEM/globus"
+	"github.com/SwissOpenEM/globus-transfer-service/internal/store"
+	"github.com/SwissOpenEM/globus-transfer-service/jobs"
+)
+
+func TestRefundUsageOfFailedSubmission(t *testing.T) {
+	s, err := store.Open(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("can't open the store: %s", err.Error())
+	}
+	defer s.Close()
+	tp := TaskPool{store: s}
+
+	accepted := time.Now().Add(-2 * time.Hour)
+	datasets := []jobs.Dataset{{Pid: "a", Size: 100}, {Pid: "b", Size: 200}, {Pid: "c", Size: 400}}
+
+	tests := []struct {
+		name       string
+		submission store.Submission
+		charged    int64
+	}{
+		{
+			name: "new job",
+			submission: store.Submission{
+				OwnerGroup: "group",
+				JobParams:  jobs.JobParams{DatasetList: datasets},
+				Transfers:  make([]globus.Transfer, len(datasets)),
+				CreatedAt:  accepted,
+			},
+			charged: 700,
+		},
+		{
+			name: "retry of some datasets",
+			submission: store.Submission{
+				OwnerGroup:       "group",
+				JobParams:        jobs.JobParams{DatasetList: datasets},
+				Transfers:        make([]globus.Transfer, 2),
+				PreviousDatasets: make([]jobs.DatasetTransfer, len(datasets)),
+				RetriedDatasets:  []int{0, 2},
+				CreatedAt:        accepted,
+			},
+			charged: 500,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// usage of the group that isn't refunded
+			if err := s.AddUsage("group", time.Now(), 10); err != nil {
+				t.Fatalf("can't add usage: %s", err.Error())
+			}
+			before, err := s.Usage("group", time.Now().Add(-QuotaDay))
+			if err != nil {
+				t.Fatalf("can't read usage: %s", err.Error())
+			}
+
+			tp.chargeUsage(test.submission)
+			charged, err := s.Usage("group", time.Now().Add(-QuotaDay))
+			if err != nil {
+				t.Fatalf("can't read usage: %s", err.Error())
+			}
+			if charged-before != test.charged {
+				t.Errorf("expected %d bytes to be charged, got %d", test.charged, charged-before)
+			}
+
+			// the transfers of the submission couldn't be submitted
+			tp.refundUsage(test.submission)
+			refunded, err := s.Usage("group", time.Now().Add(-QuotaDay))
+			if err != nil {
+				t.Fatalf("can't read usage: %s", err.Error())
+			}
+			if refunded != before {
+				t.Errorf("expected the usage to be back to %d bytes after the refund, got %d", before, refunded)
+			}
+		})
+	}
+}
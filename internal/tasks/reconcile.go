@@ -0,0 +1,304 @@
+package tasks
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/SwissOpenEM/globus-transfer-service/internal/store"
+	"github.com/SwissOpenEM/globus-transfer-service/jobs"
+)
+
+// the number of jobs fetched at once when listing the unfinished jobs from scicat
+const reconciliationPageSize = 100
+
+type ReconciliationAction string
+
+const (
+	// the transfers are still ongoing, and are tracked again
+	Resumed ReconciliationAction = "resumed"
+	// the transfers ended while the service was down, and the job was updated accordingly
+	Finalized ReconciliationAction = "finalized"
+	// the job can't be tracked, for instance because it has no globus task, so it was failed
+	Abandoned ReconciliationAction = "abandoned"
+	// the submission of the job was interrupted after all its globus tasks were submitted, and the job was started
+	SubmissionCompleted ReconciliationAction = "submission completed"
+	// the job was waiting to be submitted to globus, or its submission was interrupted before all its globus tasks
+	// were submitted and they were cancelled, so it waits in the queue again
+	SubmissionQueued ReconciliationAction = "submission queued"
+	// the submission of the job was interrupted before it was queued, and was undone
+	SubmissionRolledBack ReconciliationAction = "submission rolled back"
+	// the submission of the job was interrupted and couldn't be completed or undone, it's attempted again at the next start
+	SubmissionUnresolved ReconciliationAction = "submission unresolved"
+)
+
+// ReconciliationReport lists what the reconciliation at startup did with each unfinished transfer job
+type ReconciliationReport struct {
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+	// where the unfinished jobs were listed from, the local store is used if scicat can't be reached
+	Source string          `json:"source"`
+	Jobs   []ReconciledJob `json:"jobs"`
+}
+
+type ReconciledJob struct {
+	ScicatJobId string               `json:"scicatJobId"`
+	Action      ReconciliationAction `json:"action"`
+	Status      jobs.JobStatus       `json:"status"`
+	Error       string               `json:"error,omitempty"`
+}
+
+// Summary counts the jobs by the action taken on them
+func (r ReconciliationReport) Summary() string {
+	counts := map[ReconciliationAction]int{}
+	for _, job := range r.Jobs {
+		counts[job.Action]++
+	}
+	return fmt.Sprintf("reconciled %d unfinished jobs from %s - resumed: %d, finalized: %d, abandoned: %d, submissions completed: %d, queued: %d, rolled back: %d, unresolved: %d",
+		len(r.Jobs), r.Source, counts[Resumed], counts[Finalized], counts[Abandoned], counts[SubmissionCompleted], counts[SubmissionQueued], counts[SubmissionRolledBack], counts[SubmissionUnresolved])
+}
+
+func (r ReconciliationReport) WriteFile(path string) error {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0644)
+}
+
+// Reconcile compares the unfinished transfer jobs with the state of their globus tasks, after the service
+// (re)started. The jobs whose transfers ended while the service was down are finalized, the ones that can't
+// be tracked are failed and the others are resumed. The jobs that were queued are queued again, and the submissions
+// that were interrupted are completed, queued again or rolled back. It runs before the pool is started.
+func (tp TaskPool) Reconcile() (ReconciliationReport, error) {
+	report := ReconciliationReport{
+		StartedAt: time.Now(),
+		Source:    "scicat",
+		Jobs:      []ReconciledJob{},
+	}
+
+	unfinishedJobs, err := tp.unfinishedScicatJobs(jobs.Waiting, jobs.Transferring, jobs.Stalled)
+	if err != nil {
+		log.Printf("can't list the unfinished jobs from SciCat, reconciling the ones of the local store instead: %s\n", err.Error())
+		report.Source = "local store"
+		unfinishedJobs, err = tp.unfinishedStoredJobs()
+		if err != nil {
+			return report, err
+		}
+	}
+
+	submissions, err := tp.store.ListSubmissions()
+	if err != nil {
+		log.Printf("can't list the queued and interrupted submissions from the local store: %s\n", err.Error())
+	}
+
+	for _, job := range unfinishedJobs {
+		if isRecordedSubmission(submissions, job) {
+			continue // the globus tasks of the job weren't all submitted yet, its submission is recovered below
+		}
+		reconciled := tp.reconcileJob(job)
+		log.Printf("'%s' scicat job - reconciliation: %s, status %s, error message: '%s'\n", reconciled.ScicatJobId, reconciled.Action, reconciled.Status, reconciled.Error)
+		report.Jobs = append(report.Jobs, reconciled)
+	}
+
+	for _, reconciled := range tp.recoverSubmissions(submissions, report.Source == "scicat") {
+		log.Printf("'%s' scicat job - reconciliation: %s, status %s, error message: '%s'\n", reconciled.ScicatJobId, reconciled.Action, reconciled.Status, reconciled.Error)
+		report.Jobs = append(report.Jobs, reconciled)
+	}
+
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+// lists all the transfer jobs with one of the statuses according to scicat. Every page is fetched before any job
+// is reconciled, as reconciling a job can change its status and so shift the following pages.
+func (tp TaskPool) unfinishedScicatJobs(statuses ...jobs.JobStatus) ([]jobs.ScicatJob, error) {
+	token, err := tp.scicatServiceUser.GetToken()
+	if err != nil {
+		return nil, err
+	}
+
+	unfinishedJobs := []jobs.ScicatJob{}
+	for skip := 0; ; skip += reconciliationPageSize {
+		filter, err := json.Marshal(map[string]any{
+			"where": map[string]any{
+				"$and": []map[string]any{
+					{"type": jobs.GlobusTransferJobType},
+					{"jobResultObject.status": map[string]any{"$in": statuses}},
+				},
+			},
+			"limits": map[string]any{
+				"limit": reconciliationPageSize,
+				"skip":  skip,
+				"order": "createdAt:asc",
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		page, err := jobs.GetJobList(tp.scicatUrl, token, string(filter))
+		if err != nil {
+			return nil, err
+		}
+		unfinishedJobs = append(unfinishedJobs, page...)
+		if len(page) < reconciliationPageSize {
+			return unfinishedJobs, nil
+		}
+	}
+}
+
+func (tp TaskPool) unfinishedStoredJobs() ([]jobs.ScicatJob, error) {
+	transfers, err := tp.store.ListTransfers()
+	if err != nil {
+		return nil, err
+	}
+	unfinishedJobs := []jobs.ScicatJob{}
+	for _, transfer := range transfers {
+		if transfer.Status.Status.IsActive() {
+			unfinishedJobs = append(unfinishedJobs, transfer.Job())
+		}
+	}
+	return unfinishedJobs, nil
+}
+
+// polls the globus tasks of a job once, then either finalizes the job or resumes tracking it
+func (tp TaskPool) reconcileJob(job jobs.ScicatJob) ReconciledJob {
+	reconciled := ReconciledJob{ScicatJobId: job.ID}
+
+	datasets, err := JobDatasetTransfers(job)
+	if err == nil && len(datasets) == 0 {
+		err = fmt.Errorf("job with id '%s' has no datasets", job.ID)
+	}
+	if err != nil {
+		reconciled.Action = Abandoned
+		reconciled.Status = jobs.Failed
+		reconciled.Error = err.Error()
+		if err := tp.abandonJob(job, err); err != nil {
+			reconciled.Error += ", and the job couldn't be updated: " + err.Error()
+		}
+		return reconciled
+	}
+
+	for i := range datasets {
+		if datasets[i].Status.IsActive() && datasets[i].GlobusTaskId == "" {
+			datasets[i].Status = jobs.Failed
+			datasets[i].Error = "the transfer has no globus task, so it can't be tracked"
+		}
+	}
+
+	if err := tp.store.PutTransfer(store.NewTransfer(job)); err != nil {
+		log.Printf("'%s' scicat job - can't record the transfer in the local store: %s\n", job.ID, err.Error())
+	}
+
+	task := tp.newTransferTask(job, datasets, !job.JobParams.Retrieval)
+	completed, err := task.updateTask()
+	if err != nil {
+		reconciled.Error = err.Error()
+	}
+	reconciled.Status = task.jobResult().Status
+
+	if err == nil && completed {
+		task.finishTask()
+		task.cleanup()
+		reconciled.Action = Finalized
+		return reconciled
+	}
+
+	// the errors of the first poll are handled by the task like any later ones
+	tp.submitTask(job, task)
+	reconciled.Action = Resumed
+	return reconciled
+}
+
+// fails a job whose transfers can't be tracked, as it would otherwise stay unfinished forever
+func (tp TaskPool) abandonJob(job jobs.ScicatJob, reason error) error {
+	jobResult := job.JobResultObject
+	jobResult.Status = jobs.Failed
+	jobResult.Error = reason.Error()
+
+	if err := tp.store.UpdateTransferStatus(job.ID, jobResult); err != nil {
+		log.Printf("'%s' scicat job - can't record the status of the transfer in the local store: %s\n", job.ID, err.Error())
+	}
+
+	token, err := tp.scicatServiceUser.GetToken()
+	if err != nil {
+		return err
+	}
+	_, err = UpdateGlobusTransferScicatJob(tp.scicatUrl, token, job.ID, "998", "the job can't be tracked by the service", jobResult)
+	return err
+}
+
+// recovers the submissions recorded in the local store, as well as the jobs left in the submitting state in scicat
+// without a local record, for instance because the store was lost
+func (tp TaskPool) recoverSubmissions(submissions []store.Submission, scicatReachable bool) []ReconciledJob {
+	if scicatReachable {
+		submittingJobs, err := tp.unfinishedScicatJobs(jobs.Submitting)
+		if err != nil {
+			log.Printf("can't list the jobs left in the submitting state from SciCat: %s\n", err.Error())
+		}
+		for _, job := range submittingJobs {
+			if !isRecordedSubmission(submissions, job) {
+				submissions = append(submissions, orphanedSubmission(job))
+			}
+		}
+	}
+
+	recovered := []ReconciledJob{}
+	for _, submission := range submissions {
+		recovered = append(recovered, tp.recoverSubmission(submission))
+	}
+	return recovered
+}
+
+func isRecordedSubmission(submissions []store.Submission, job jobs.ScicatJob) bool {
+	for _, submission := range submissions {
+		if submission.ScicatJobId == job.ID || submission.ID == job.JobParams.SubmissionId {
+			return true
+		}
+	}
+	return false
+}
+
+// rebuilds the submission of a job left in the submitting state from the job itself, without the ids of the
+// globus tasks that were submitted, which are looked for by their label
+func orphanedSubmission(job jobs.ScicatJob) store.Submission {
+	submission := store.Submission{
+		ID:            job.JobParams.SubmissionId,
+		State:         store.SubmissionSubmitting,
+		OwnerUser:     job.OwnerUser,
+		OwnerGroup:    job.OwnerGroup,
+		JobParams:     job.JobParams,
+		ScicatJobId:   job.ID,
+		GlobusTaskIds: make([]string, len(job.JobParams.DatasetList)),
+		CreatedAt:     job.CreatedAt,
+	}
+
+	// the datasets of a retried job that weren't retried kept their transfers, the retried ones fail if the
+	// submission can't be completed
+	if !isRetriedJob(job) {
+		return submission
+	}
+	submission.PreviousDatasets = slices.Clone(job.JobResultObject.Datasets)
+	submission.RetriedDatasets = []int{}
+	for i, dataset := range job.JobResultObject.Datasets {
+		if dataset.Status == jobs.Submitting {
+			submission.PreviousDatasets[i].Status = jobs.Failed
+			submission.PreviousDatasets[i].Error = "the submission of the retry was interrupted"
+			submission.RetriedDatasets = append(submission.RetriedDatasets, i)
+		}
+	}
+	submission.GlobusTaskIds = make([]string, len(submission.RetriedDatasets))
+	return submission
+}
+
+// whether a job left in the submitting state was being retried, in which case some of its datasets were transferred
+// before, or weren't retried
+func isRetriedJob(job jobs.ScicatJob) bool {
+	return slices.ContainsFunc(job.JobResultObject.Datasets, func(dataset jobs.DatasetTransfer) bool {
+		return dataset.Status != jobs.Submitting || len(dataset.PreviousGlobusTaskIds) > 0
+	})
+}
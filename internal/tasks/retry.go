@@ -0,0 +1,68 @@
+package tasks
+
+import (
+	"errors"
+	"math/rand/v2"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const (
+	retryBaseDelay = time.Second
+	retryMaxDelay  = 5 * time.Minute
+)
+
+// transientError marks an error as transient regardless of its cause
+type transientError struct {
+	err error
+}
+
+func (e *transientError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transientError) Unwrap() error {
+	return e.err
+}
+
+// the globus client only reports the status code of failed requests in its error messages, in a different format
+// for the monitoring requests, the task submissions and the submission id requests
+var globusStatusRegexp = regexp.MustCompile(`(?:Non-Successful Status:|unknown http code|unexpected status for submission id request:) (\d+)`)
+
+// isTransientError returns whether a request failed for a reason that can go away by itself, which are
+// network errors, server errors and rate limiting. Failures reported by globus for the task itself aren't.
+func isTransientError(err error) bool {
+	var markedErr *transientError
+	if errors.As(err, &markedErr) {
+		return true
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return true
+	}
+	var statusErr *ScicatStatusError
+	if errors.As(err, &statusErr) {
+		return isTransientStatus(statusErr.StatusCode)
+	}
+	if match := globusStatusRegexp.FindStringSubmatch(err.Error()); match != nil {
+		statusCode, _ := strconv.Atoi(match[1])
+		return isTransientStatus(statusCode)
+	}
+	return false
+}
+
+func isTransientStatus(statusCode int) bool {
+	return statusCode == 429 || statusCode >= 500
+}
+
+// the delay before the given attempt at recovering from a transient error, which doubles with every
+// attempt up to a maximum and is randomly shortened by up to half so that tasks don't retry all at once
+func backoffDelay(attempt int) time.Duration {
+	delay := retryMaxDelay
+	if attempt < 20 {
+		delay = min(retryBaseDelay<<(attempt-1), retryMaxDelay)
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
@@ -5,12 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
+	"time"
 
-	"github.com/SwissOpenEM/globus-transfer-service/internal/serviceuser"
+	"github.com/SwissOpenEM/globus-transfer-service/internal/store"
 	"github.com/SwissOpenEM/globus-transfer-service/jobs"
 )
 
@@ -33,6 +34,16 @@ type scicatErrorResp struct {
 	Message string `json:"Message"`
 }
 
+// ScicatStatusError is returned when SciCat answers a request with an unexpected status code
+type ScicatStatusError struct {
+	StatusCode int
+	msg        string
+}
+
+func (e *ScicatStatusError) Error() string {
+	return e.msg
+}
+
 type JobDeleteNotExist struct {
 	scicatErrorResp
 }
@@ -41,23 +52,18 @@ func (e *JobDeleteNotExist) Error() string {
 	return e.Message
 }
 
-func CreateGlobusTransferScicatJob(scicatUrl string, scicatToken string, ownerGroup string, datasetPid string, globusTaskId string) (jobs.ScicatJob, error) {
+// CreateQueuedScicatJob creates the job of a transfer that waits in the queue until its globus tasks can be submitted
+func CreateQueuedScicatJob(scicatUrl string, scicatToken string, ownerUser string, ownerGroup string, jobParams jobs.JobParams) (jobs.ScicatJob, error) {
 	url, err := url.JoinPath(scicatUrl, "api", "v4", "jobs")
 	if err != nil {
 		return jobs.ScicatJob{}, err
 	}
 
 	reqBody, err := json.Marshal(scicatJobPost{
-		Type:       "globus_transfer_job",
+		Type:       jobs.GlobusTransferJobType,
+		OwnerUser:  ownerUser,
 		OwnerGroup: ownerGroup,
-		JobParams: jobs.JobParams{
-			DatasetList: []jobs.Dataset{
-				{
-					Pid:   datasetPid,
-					Files: []string{},
-				},
-			},
-		},
+		JobParams:  jobParams,
 	})
 	if err != nil {
 		return jobs.ScicatJob{}, err
@@ -95,14 +101,108 @@ func CreateGlobusTransferScicatJob(scicatUrl string, scicatToken string, ownerGr
 	if err != nil {
 		return job, err
 	}
+	return QueueScicatJob(scicatUrl, scicatToken, job.ID, unsubmittedJobResult(jobParams, jobs.Waiting, ""))
+}
+
+// QueueScicatJob sets the job as waiting in the queue, which is also done when its submission to globus is retried
+func QueueScicatJob(scicatUrl string, scicatToken string, jobId string, jobResult jobs.JobResultObject) (jobs.ScicatJob, error) {
+	return UpdateGlobusTransferScicatJob(scicatUrl, scicatToken, jobId, "000", "waiting to be submitted to globus", jobResult)
+}
+
+// SubmittingScicatJob sets the job as leaving the queue, while its globus tasks are submitted
+func SubmittingScicatJob(scicatUrl string, scicatToken string, jobId string, jobResult jobs.JobResultObject) (jobs.ScicatJob, error) {
+	return UpdateGlobusTransferScicatJob(scicatUrl, scicatToken, jobId, "000", "submitting", jobResult)
+}
+
+// FailUnsubmittedScicatJob fails a job whose globus tasks couldn't be submitted
+func FailUnsubmittedScicatJob(scicatUrl string, scicatToken string, jobId string, jobResult jobs.JobResultObject) (jobs.ScicatJob, error) {
+	return UpdateGlobusTransferScicatJob(scicatUrl, scicatToken, jobId, "994", "the transfer couldn't be submitted to globus", jobResult)
+}
+
+// CancelUnsubmittedScicatJob cancels a job that was still waiting in the queue
+func CancelUnsubmittedScicatJob(scicatUrl string, scicatToken string, jobId string, jobResult jobs.JobResultObject) (jobs.ScicatJob, error) {
+	return UpdateGlobusTransferScicatJob(scicatUrl, scicatToken, jobId, "003", "cancelled", jobResult)
+}
+
+// RevertRetriedScicatJob restores a job whose retry couldn't be submitted to the state it had before the retry
+func RevertRetriedScicatJob(scicatUrl string, scicatToken string, jobId string, previousDatasets []jobs.DatasetTransfer) (jobs.ScicatJob, error) {
+	return UpdateGlobusTransferScicatJob(scicatUrl, scicatToken, jobId, "994", "the retry couldn't be submitted to globus", NewJobResult(previousDatasets))
+}
+
+// the result of the job of a submission whose globus tasks weren't submitted, where the datasets to submit have the
+// given status. The other datasets of a retried job keep the state they had before the retry.
+func submissionJobResult(submission store.Submission, status jobs.JobStatus, reason string) jobs.JobResultObject {
+	if !submission.IsRetry() {
+		return unsubmittedJobResult(submission.JobParams, status, reason)
+	}
+	datasets := slices.Clone(submission.PreviousDatasets)
+	for _, i := range submission.RetriedDatasets {
+		datasets[i] = retriedDatasetTransfer(submission.PreviousDatasets[i], "")
+		datasets[i].Status = status
+		datasets[i].Error = reason
+	}
+	jobResult := NewJobResult(datasets)
+	if !status.IsFinal() {
+		jobResult.Status = status
+	}
+	return jobResult
+}
+
+// the transfer of a dataset that's transferred again with the given globus task, which keeps the previous ones
+func retriedDatasetTransfer(previous jobs.DatasetTransfer, globusTaskId string) jobs.DatasetTransfer {
+	previousGlobusTaskIds := slices.Clone(previous.PreviousGlobusTaskIds)
+	if previous.GlobusTaskId != "" {
+		previousGlobusTaskIds = append(previousGlobusTaskIds, previous.GlobusTaskId)
+	}
+	return jobs.DatasetTransfer{
+		Pid:                   previous.Pid,
+		GlobusTaskId:          globusTaskId,
+		Status:                jobs.Transferring,
+		PreviousGlobusTaskIds: previousGlobusTaskIds,
+	}
+}
 
-	return UpdateGlobusTransferScicatJob(scicatUrl, scicatToken, job.ID, "001", "started", jobs.JobResultObject{
+// the result of a job without globus tasks, where all datasets have the same status
+func unsubmittedJobResult(jobParams jobs.JobParams, status jobs.JobStatus, reason string) jobs.JobResultObject {
+	datasets := make([]jobs.DatasetTransfer, len(jobParams.DatasetList))
+	for i, dataset := range jobParams.DatasetList {
+		datasets[i] = jobs.DatasetTransfer{
+			Pid:    dataset.Pid,
+			Status: status,
+			Error:  reason,
+		}
+	}
+	return jobs.JobResultObject{
+		Status:   status,
+		Error:    reason,
+		Datasets: datasets,
+	}
+}
+
+// ConfirmSubmittedScicatJob starts the job once the globus task of each of its datasets was submitted
+func ConfirmSubmittedScicatJob(scicatUrl string, scicatToken string, jobId string, datasetTransfers []jobs.DatasetTransfer, deadline *time.Time) (jobs.ScicatJob, error) {
+	datasets := make([]jobs.DatasetTransfer, len(datasetTransfers))
+	for i, datasetTransfer := range datasetTransfers {
+		datasets[i] = jobs.DatasetTransfer{
+			Pid:          datasetTransfer.Pid,
+			GlobusTaskId: datasetTransfer.GlobusTaskId,
+			Status:       jobs.Transferring,
+		}
+	}
+	globusTaskId := ""
+	if len(datasets) == 1 {
+		globusTaskId = datasets[0].GlobusTaskId
+	}
+
+	return UpdateGlobusTransferScicatJob(scicatUrl, scicatToken, jobId, "001", "started", jobs.JobResultObject{
 		GlobusTaskId:     globusTaskId,
 		BytesTransferred: 0,
 		FilesTransferred: 0,
 		FilesTotal:       0,
 		Status:           jobs.Transferring,
 		Error:            "",
+		Datasets:         datasets,
+		Deadline:         deadline,
 	})
 }
 
@@ -144,7 +244,10 @@ func UpdateGlobusTransferScicatJob(scicatUrl string, scicatToken string, jobId s
 		break
 	default:
 		body, _ := io.ReadAll(resp.Body)
-		return jobs.ScicatJob{}, fmt.Errorf("unknown status encountered: '%s', body: '%s'", resp.Status, string(body))
+		return jobs.ScicatJob{}, &ScicatStatusError{
+			StatusCode: resp.StatusCode,
+			msg:        fmt.Sprintf("unknown status encountered: '%s', body: '%s'", resp.Status, string(body)),
+		}
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -157,6 +260,13 @@ func UpdateGlobusTransferScicatJob(scicatUrl string, scicatToken string, jobId s
 	return job, err
 }
 
+// RestartGlobusTransferScicatJob resets the status of a transfer job of which some datasets are transferred again
+func RestartGlobusTransferScicatJob(scicatUrl string, scicatToken string, jobId string, datasetTransfers []jobs.DatasetTransfer, deadline *time.Time) (jobs.ScicatJob, error) {
+	jobResult := NewJobResult(datasetTransfers)
+	jobResult.Deadline = deadline
+	return UpdateGlobusTransferScicatJob(scicatUrl, scicatToken, jobId, "001", "restarted", jobResult)
+}
+
 func DeleteScicatJob(scicatUrl string, scicatToken string, jobId string) error {
 	url, err := url.JoinPath(scicatUrl, "api", "v4", "jobs", url.QueryEscape(jobId))
 	if err != nil {
@@ -200,30 +310,31 @@ func DeleteScicatJob(scicatUrl string, scicatToken string, jobId string) error {
 	return nil
 }
 
-func RestoreGlobusTransferJobsFromScicat(scicatUrl string, serviceUser serviceuser.ScicatServiceUser, pool TaskPool) error {
-	token, err := serviceUser.GetToken()
-	if err != nil {
-		return err
-	}
-	unfinishedJobs, err := jobs.GetJobList(scicatUrl, token, `{"where":{"type":"globus_transfer_job","jobResultObject.completed":false,"jobResultObject.error":""}}`)
-	if err != nil {
-		return err
+// JobDatasetTransfers returns the state of the transfer of each dataset of a transfer job
+func JobDatasetTransfers(job jobs.ScicatJob) ([]jobs.DatasetTransfer, error) {
+	if len(job.JobResultObject.Datasets) > 0 {
+		datasets := make([]jobs.DatasetTransfer, len(job.JobResultObject.Datasets))
+		copy(datasets, job.JobResultObject.Datasets)
+		return datasets, nil
 	}
 
-	for _, job := range unfinishedJobs {
-		if job.JobResultObject.GlobusTaskId == "" {
-			log.Printf("Warning: job with id '%s' has no globus task id, so it cannot be resumed\n", job.ID)
-			continue
-		}
-		if len(job.JobParams.DatasetList) > 1 {
-			log.Printf("Warning: job with id '%s' has more than one associated dataset (a total of %d), which is not currently supported\n", job.ID, len(job.JobParams.DatasetList))
-			continue
-		}
-		if len(job.JobParams.DatasetList) <= 0 {
-			log.Printf("Warning: job with id '%s' has no datasets associated, so it cannot be resumed\n", job.ID)
-		}
-		pool.AddTransferTask(job.JobResultObject.GlobusTaskId, job.JobParams.DatasetList[0].Pid, job.ID)
+	// jobs created before batch transfers were introduced only have a single task id at the top level
+	if job.JobResultObject.GlobusTaskId == "" {
+		return nil, fmt.Errorf("job with id '%s' has no globus task id", job.ID)
 	}
-
-	return nil
+	if len(job.JobParams.DatasetList) != 1 {
+		return nil, fmt.Errorf("job with id '%s' has %d associated datasets but a single globus task id", job.ID, len(job.JobParams.DatasetList))
+	}
+	return []jobs.DatasetTransfer{
+		{
+			Pid:                   job.JobParams.DatasetList[0].Pid,
+			GlobusTaskId:          job.JobResultObject.GlobusTaskId,
+			BytesTransferred:      job.JobResultObject.BytesTransferred,
+			FilesTransferred:      job.JobResultObject.FilesTransferred,
+			FilesTotal:            job.JobResultObject.FilesTotal,
+			Status:                job.JobResultObject.Status,
+			Error:                 job.JobResultObject.Error,
+			PreviousGlobusTaskIds: job.JobResultObject.PreviousGlobusTaskIds,
+		},
+	}, nil
 }
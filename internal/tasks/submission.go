@@ -0,0 +1,552 @@
+package tasks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/SwissOpenEM/globus"
+	"github.com/SwissOpenEM/globus-transfer-service/internal/store"
+	"github.com/SwissOpenEM/globus-transfer-service/jobs"
+	"github.com/google/uuid"
+)
+
+// the label of the globus tasks submitted for a job holds the job id and the position of the dataset in the
+// job, which finds the tasks of a submission that was interrupted before their ids were recorded
+const (
+	submissionLabelFormat = "scicat job %s #%d"
+	maxGlobusLabelLength  = 128
+)
+
+var submissionLabelRegexp = regexp.MustCompile(`scicat job (\S+) #(\d+)$`)
+
+// how many of the most recent globus tasks are searched for the tasks of an interrupted submission
+const (
+	submissionRecoveryScanLimit = 1000
+	submissionRecoveryPageSize  = 100
+)
+
+// SubmissionStage is the stage of the submission of a transfer that failed: the login of the service user,
+// recording the submission in the local store, or creating or updating its scicat job
+type SubmissionStage string
+
+const (
+	StageScicatLogin SubmissionStage = "scicat login"
+	StageLocalStore  SubmissionStage = "local store"
+	StageScicat      SubmissionStage = "scicat"
+)
+
+// SubmissionError is returned by SubmitTransfer and RetryTransfer when a stage of the submission failed, once it
+// was rolled back
+type SubmissionError struct {
+	Stage SubmissionStage
+	Err   error
+}
+
+func (e *SubmissionError) Error() string {
+	return fmt.Sprintf("submission failed at the %s stage: %s", e.Stage, e.Err.Error())
+}
+
+func (e *SubmissionError) Unwrap() error {
+	return e.Err
+}
+
+// SubmitTransfer accepts a new transfer job in a way that can be recovered from a crash at any point: the
+// submission is recorded locally first, then its scicat job is created and waits in the queue until the limits of
+// active globus tasks allow submitting its transfers. A submission that fails is rolled back, one that was
+// interrupted is completed, queued again or rolled back when reconciling. transfers holds the globus transfer of
+// each dataset of the dataset list of jobParams.
+func (tp TaskPool) SubmitTransfer(ownerUser string, ownerGroup string, jobParams jobs.JobParams, transfers []globus.Transfer) (jobs.ScicatJob, error) {
+	token, err := tp.scicatServiceUser.GetToken()
+	if err != nil {
+		return jobs.ScicatJob{}, &SubmissionError{Stage: StageScicatLogin, Err: err}
+	}
+
+	submission := store.Submission{
+		ID:            uuid.NewString(),
+		State:         store.SubmissionPending,
+		OwnerUser:     ownerUser,
+		OwnerGroup:    ownerGroup,
+		JobParams:     jobParams,
+		Transfers:     transfers,
+		GlobusTaskIds: make([]string, len(transfers)),
+		CreatedAt:     time.Now(),
+	}
+	submission.JobParams.SubmissionId = submission.ID
+	if err := tp.store.PutSubmission(submission); err != nil {
+		return jobs.ScicatJob{}, &SubmissionError{Stage: StageLocalStore, Err: err}
+	}
+
+	job, err := CreateQueuedScicatJob(tp.scicatUrl, token, ownerUser, ownerGroup, submission.JobParams)
+	if err != nil {
+		_ = tp.rollbackSubmission(submission)
+		return jobs.ScicatJob{}, &SubmissionError{Stage: StageScicat, Err: err}
+	}
+	submission.ScicatJobId = job.ID
+	submission.State = store.SubmissionQueued
+	if err := tp.store.PutSubmission(submission); err != nil {
+		_ = tp.rollbackSubmission(submission)
+		return jobs.ScicatJob{}, &SubmissionError{Stage: StageLocalStore, Err: err}
+	}
+
+	tp.enqueue(submission)
+	tp.chargeUsage(submission)
+	return job, nil
+}
+
+// RetryTransfer queues the transfers of some datasets of an ended job again, as part of the same job. Like a new
+// submission, the retry is recorded locally first, then the job waits in the queue until its transfers can be
+// submitted. previousDatasets holds the state of the transfer of each dataset of the job, retried the position in
+// the dataset list of the dataset of each of the transfers, and jobParams the parameters of the job for the retry.
+func (tp TaskPool) RetryTransfer(job jobs.ScicatJob, previousDatasets []jobs.DatasetTransfer, retried []int, jobParams jobs.JobParams, transfers []globus.Transfer) (jobs.ScicatJob, error) {
+	token, err := tp.scicatServiceUser.GetToken()
+	if err != nil {
+		return jobs.ScicatJob{}, &SubmissionError{Stage: StageScicatLogin, Err: err}
+	}
+
+	submission := store.Submission{
+		ID:               uuid.NewString(),
+		State:            store.SubmissionPending,
+		OwnerUser:        job.OwnerUser,
+		OwnerGroup:       job.OwnerGroup,
+		JobParams:        jobParams,
+		ScicatJobId:      job.ID,
+		Transfers:        transfers,
+		GlobusTaskIds:    make([]string, len(transfers)),
+		PreviousDatasets: previousDatasets,
+		RetriedDatasets:  retried,
+		CreatedAt:        time.Now(),
+	}
+	if err := tp.store.PutSubmission(submission); err != nil {
+		return jobs.ScicatJob{}, &SubmissionError{Stage: StageLocalStore, Err: err}
+	}
+
+	queuedJob, err := QueueScicatJob(tp.scicatUrl, token, job.ID, submissionJobResult(submission, jobs.Waiting, ""))
+	if err != nil {
+		_ = tp.rollbackSubmission(submission)
+		return jobs.ScicatJob{}, &SubmissionError{Stage: StageScicat, Err: err}
+	}
+	submission.State = store.SubmissionQueued
+	if err := tp.store.PutSubmission(submission); err != nil {
+		_ = tp.rollbackSubmission(submission)
+		return jobs.ScicatJob{}, &SubmissionError{Stage: StageLocalStore, Err: err}
+	}
+
+	tp.enqueue(submission)
+	tp.chargeUsage(submission)
+	return queuedJob, nil
+}
+
+// submits the globus tasks of a job that left the queue, with the job id in their label, then confirms and tracks
+// the job. If a transfer can't be submitted, the tasks already submitted are cancelled and the job is queued again
+// if the error is transient, or fails otherwise.
+func (tp TaskPool) submitQueued(submission store.Submission) {
+	var requeued *store.Submission
+	defer func() { tp.doneSubmitting(submission.ScicatJobId, requeued) }()
+
+	now := time.Now()
+	submission.Deadline = transfersDeadline(submission.JobParams.TransferOptions, now)
+	if submission.Deadline != nil && !submission.Deadline.After(now) {
+		if err := tp.failSubmission(submission, errors.New("the deadline of the transfer passed while it waited in the queue")); err != nil {
+			log.Printf("'%s' scicat job - can't fail the job whose deadline passed in the queue: %s\n", submission.ScicatJobId, err.Error())
+		}
+		return
+	}
+
+	submission.State = store.SubmissionSubmitting
+	if err := tp.store.PutSubmission(submission); err != nil {
+		log.Printf("'%s' scicat job - can't record the submission of its transfers in the local store, it's queued again: %s\n", submission.ScicatJobId, err.Error())
+		submission.State = store.SubmissionQueued
+		requeued = &submission
+		return
+	}
+	if token, err := tp.scicatServiceUser.GetToken(); err == nil {
+		if _, err := SubmittingScicatJob(tp.scicatUrl, token, submission.ScicatJobId, submissionJobResult(submission, jobs.Submitting, "")); err != nil {
+			log.Printf("'%s' scicat job - can't update its status before submitting its transfers: %s\n", submission.ScicatJobId, err.Error())
+		}
+	}
+	tp.publishQueued(submission, jobs.Submitting, "")
+
+	for i, transfer := range submission.Transfers {
+		datasetIndex := submission.DatasetIndex(i)
+		label := submissionLabel(transfer.Label, submission.ScicatJobId, datasetIndex)
+		transfer.Label = &label
+		if submission.Deadline != nil {
+			deadline := submission.Deadline.UTC().Format(time.RFC3339)
+			transfer.Deadline = &deadline
+		}
+		result, err := tp.globusClient.TransferPostTask(transfer)
+		if err != nil {
+			requeued = tp.abortSubmission(submission, fmt.Errorf("can't submit the globus transfer of '%s': %w", submission.JobParams.DatasetList[datasetIndex].Pid, err))
+			return
+		}
+
+		submission.GlobusTaskIds[i] = result.TaskId
+		if err := tp.store.PutSubmission(submission); err != nil {
+			requeued = tp.abortSubmission(submission, err)
+			return
+		}
+	}
+
+	if _, err := tp.confirmSubmission(submission); err != nil {
+		requeued = tp.abortSubmission(submission, err)
+	}
+}
+
+// cancels the globus tasks of a submission that couldn't be completed, then returns it to be queued again if the
+// error is transient, or fails its job otherwise. A submission queued again waits for an exponential backoff before
+// being submitted again, and fails after maxConsecutiveErrors transient errors in a row. If a task can't be
+// cancelled, the submission is left to the next reconciliation.
+func (tp TaskPool) abortSubmission(submission store.Submission, cause error) *store.Submission {
+	log.Printf("'%s' scicat job - the submission of its transfers failed: %s\n", submission.ScicatJobId, cause.Error())
+	if err := tp.cancelSubmittedTasks(&submission); err != nil {
+		log.Printf("'%s' scicat job - can't cancel the globus tasks of the failed submission, it will be resolved at the next start: %s\n", submission.ScicatJobId, err.Error())
+		return nil
+	}
+	submission.FailedAttempts++
+	if isTransientError(cause) && submission.FailedAttempts <= tp.maxConsecutiveErrors {
+		delay := backoffDelay(submission.FailedAttempts)
+		submission.NotBefore = time.Now().Add(delay)
+		log.Printf("'%s' scicat job - transient error (%d of %d), submitting again in %s\n", submission.ScicatJobId, submission.FailedAttempts, tp.maxConsecutiveErrors, delay.Round(time.Millisecond))
+		requeued := tp.requeueSubmission(submission)
+		return &requeued
+	}
+	if err := tp.failSubmission(submission, cause); err != nil {
+		log.Printf("'%s' scicat job - can't fail the job of the failed submission: %s\n", submission.ScicatJobId, err.Error())
+	}
+	return nil
+}
+
+// cancels the globus tasks that were submitted for a submission, and forgets them once they're all cancelled
+func (tp TaskPool) cancelSubmittedTasks(submission *store.Submission) error {
+	errs := []error{}
+	for _, globusTaskId := range submission.GlobusTaskIds {
+		if globusTaskId == "" {
+			continue
+		}
+		if err := tp.cancelGlobusTask(globusTaskId); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	submission.GlobusTaskIds = make([]string, len(submission.GlobusTaskIds))
+	return nil
+}
+
+// records that a submission whose globus tasks were cancelled is queued again, and returns it to be put back in the
+// queue, at its original position
+func (tp TaskPool) requeueSubmission(submission store.Submission) store.Submission {
+	submission.State = store.SubmissionQueued
+	submission.Deadline = nil
+	if err := tp.store.PutSubmission(submission); err != nil {
+		log.Printf("'%s' scicat job - can't record that the submission is queued again in the local store: %s\n", submission.ScicatJobId, err.Error())
+	}
+	if token, err := tp.scicatServiceUser.GetToken(); err == nil {
+		if _, err := QueueScicatJob(tp.scicatUrl, token, submission.ScicatJobId, submissionJobResult(submission, jobs.Waiting, "")); err != nil {
+			log.Printf("'%s' scicat job - can't update its status after queuing it again: %s\n", submission.ScicatJobId, err.Error())
+		}
+	}
+	return submission
+}
+
+// fails the job of a submission whose transfers can't be submitted, and forgets the submission
+func (tp TaskPool) failSubmission(submission store.Submission, cause error) error {
+	token, err := tp.scicatServiceUser.GetToken()
+	if err != nil {
+		return err
+	}
+	if _, err := FailUnsubmittedScicatJob(tp.scicatUrl, token, submission.ScicatJobId, submissionJobResult(submission, jobs.Failed, cause.Error())); err != nil {
+		return err
+	}
+	tp.refundUsage(submission)
+	tp.publishQueued(submission, jobs.Failed, cause.Error())
+	return tp.store.DeleteSubmission(submission.ID)
+}
+
+// starts the scicat job of a submission whose globus tasks were all submitted, and tracks it
+func (tp TaskPool) confirmSubmission(submission store.Submission) (jobs.ScicatJob, error) {
+	token, err := tp.scicatServiceUser.GetToken()
+	if err != nil {
+		return jobs.ScicatJob{}, err
+	}
+
+	datasetTransfers := submissionDatasetTransfers(submission)
+	deadline := submission.Deadline
+	if deadline == nil && submission.JobParams.TransferOptions != nil {
+		// the submissions recorded before the deadline was set when leaving the queue
+		deadline = submission.JobParams.TransferOptions.Deadline
+	}
+
+	var job jobs.ScicatJob
+	if submission.IsRetry() {
+		job, err = RestartGlobusTransferScicatJob(tp.scicatUrl, token, submission.ScicatJobId, datasetTransfers, deadline)
+	} else {
+		job, err = ConfirmSubmittedScicatJob(tp.scicatUrl, token, submission.ScicatJobId, datasetTransfers, deadline)
+	}
+	if err != nil {
+		return jobs.ScicatJob{}, err
+	}
+	if err := tp.store.DeleteSubmission(submission.ID); err != nil {
+		log.Printf("'%s' scicat job - can't delete the confirmed submission from the local store: %s\n", job.ID, err.Error())
+	}
+	tp.ResumeTransferTask(job, datasetTransfers)
+	return job, nil
+}
+
+// the deadline of transfers that leave the queue at the given time, which is the requested one, or else the maximum
+// duration of the transfers from then. Transfers without either have no deadline.
+func transfersDeadline(options *jobs.TransferOptions, now time.Time) *time.Time {
+	if options == nil {
+		return nil
+	}
+	if options.Deadline != nil || options.MaxDuration == 0 {
+		return options.Deadline
+	}
+	deadline := now.Add(time.Duration(options.MaxDuration) * time.Second)
+	return &deadline
+}
+
+// the transfer of each dataset of the job of a submission whose globus tasks were all submitted
+func submissionDatasetTransfers(submission store.Submission) []jobs.DatasetTransfer {
+	if submission.IsRetry() {
+		datasetTransfers := slices.Clone(submission.PreviousDatasets)
+		for i, globusTaskId := range submission.GlobusTaskIds {
+			datasetIndex := submission.DatasetIndex(i)
+			datasetTransfers[datasetIndex] = retriedDatasetTransfer(submission.PreviousDatasets[datasetIndex], globusTaskId)
+		}
+		return datasetTransfers
+	}
+
+	datasetTransfers := make([]jobs.DatasetTransfer, len(submission.GlobusTaskIds))
+	for i, globusTaskId := range submission.GlobusTaskIds {
+		datasetTransfers[i] = jobs.DatasetTransfer{
+			Pid:          submission.JobParams.DatasetList[i].Pid,
+			GlobusTaskId: globusTaskId,
+		}
+	}
+	return datasetTransfers
+}
+
+// rolls back a submission by cancelling its globus tasks and deleting its scicat job, or restoring the job to its
+// state before the retry for retries. The submission is only
+// removed from the store once that succeeded, so that the rollback is attempted again when reconciling.
+func (tp TaskPool) rollbackSubmission(submission store.Submission) error {
+	submission.State = store.SubmissionRollingBack
+	if err := tp.store.PutSubmission(submission); err != nil {
+		log.Printf("'%s' submission - can't record its rollback in the local store: %s\n", submission.ID, err.Error())
+	}
+
+	errs := []error{}
+	if err := tp.cancelSubmittedTasks(&submission); err != nil {
+		errs = append(errs, err)
+	}
+
+	token, err := tp.scicatServiceUser.GetToken()
+	if err != nil {
+		errs = append(errs, err)
+	} else if submission.IsRetry() {
+		if _, err := RevertRetriedScicatJob(tp.scicatUrl, token, submission.ScicatJobId, submission.PreviousDatasets); err != nil {
+			errs = append(errs, err)
+		}
+	} else {
+		jobId := submission.ScicatJobId
+		if jobId == "" {
+			// the job might have been created without its id being recorded
+			job, found, err := findSubmissionJob(tp.scicatUrl, token, submission.ID)
+			if err != nil {
+				errs = append(errs, err)
+			} else if found {
+				jobId = job.ID
+			}
+		}
+		if jobId != "" {
+			err := DeleteScicatJob(tp.scicatUrl, token, jobId)
+			notExistErr := &JobDeleteNotExist{}
+			if err != nil && !errors.As(err, &notExistErr) {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		err := errors.Join(errs...)
+		log.Printf("'%s' submission - rollback failed, it will be attempted again at the next start: %s\n", submission.ID, err.Error())
+		return err
+	}
+	if err := tp.store.DeleteSubmission(submission.ID); err != nil {
+		log.Printf("'%s' submission - can't delete the rolled back submission from the local store: %s\n", submission.ID, err.Error())
+	}
+	if submission.IsRetry() {
+		tp.publish(TransferEvent{ScicatJobId: submission.ScicatJobId, Status: NewJobResult(submission.PreviousDatasets)})
+	} else if submission.ScicatJobId != "" {
+		tp.publishQueued(submission, jobs.Failed, "the submission of the transfer was rolled back")
+	}
+	return nil
+}
+
+// cancels a globus task and checks that it did end, as the result of a cancellation request isn't conclusive
+func (tp TaskPool) cancelGlobusTask(globusTaskId string) error {
+	_, cancelErr := tp.globusClient.TransferCancelTaskByID(globusTaskId)
+	task, err := tp.globusClient.TransferGetTaskByID(globusTaskId)
+	if err != nil {
+		return fmt.Errorf("can't check that the globus task '%s' was cancelled: %w", globusTaskId, err)
+	}
+	if task.Status == "ACTIVE" || task.Status == "INACTIVE" {
+		if cancelErr != nil {
+			return fmt.Errorf("the globus task '%s' couldn't be cancelled: %w", globusTaskId, cancelErr)
+		}
+		return fmt.Errorf("the globus task '%s' is still %s after being cancelled", globusTaskId, task.Status)
+	}
+	return nil
+}
+
+// resumes a submission that was interrupted. Queued jobs are queued again. A job whose globus tasks were being
+// submitted is completed if the tasks of all its datasets were submitted, otherwise its tasks are cancelled and it's
+// queued again. The other submissions are rolled back.
+func (tp TaskPool) recoverSubmission(submission store.Submission) ReconciledJob {
+	reconciled := ReconciledJob{ScicatJobId: submission.ScicatJobId}
+
+	if submission.State == store.SubmissionQueued {
+		tp.enqueue(submission)
+		reconciled.Action = SubmissionQueued
+		reconciled.Status = jobs.Waiting
+		return reconciled
+	}
+
+	// globus is only contacted once the job left the queue, so pending submissions have no tasks to look for
+	if submission.State == store.SubmissionSubmitting {
+		if err := tp.findSubmittedTasks(&submission); err != nil {
+			reconciled.Action = SubmissionUnresolved
+			reconciled.Status = jobs.Submitting
+			reconciled.Error = "can't look for the globus tasks of the submission: " + err.Error()
+			return reconciled
+		}
+
+		if !hasMissingTaskIds(submission.GlobusTaskIds) {
+			job, err := tp.confirmSubmission(submission)
+			if err == nil {
+				reconciled.Action = SubmissionCompleted
+				reconciled.Status = job.JobResultObject.Status
+				return reconciled
+			}
+			reconciled.Error = "can't confirm the submission: " + err.Error()
+		}
+
+		// the transfers are unknown for the jobs left in the submitting state without a local record
+		if len(submission.Transfers) == len(submission.GlobusTaskIds) {
+			if err := tp.cancelSubmittedTasks(&submission); err != nil {
+				reconciled.Action = SubmissionUnresolved
+				reconciled.Status = jobs.Submitting
+				reconciled.Error = "can't cancel the globus tasks of the submission: " + err.Error()
+				return reconciled
+			}
+			tp.enqueue(tp.requeueSubmission(submission))
+			reconciled.Action = SubmissionQueued
+			reconciled.Status = jobs.Waiting
+			return reconciled
+		}
+	}
+
+	if err := tp.rollbackSubmission(submission); err != nil {
+		reconciled.Action = SubmissionUnresolved
+		reconciled.Status = jobs.Submitting
+		reconciled.Error = err.Error()
+		return reconciled
+	}
+	reconciled.Action = SubmissionRolledBack
+	return reconciled
+}
+
+// looks for the globus tasks of the datasets of a submission whose ids weren't recorded, by their label
+func (tp TaskPool) findSubmittedTasks(submission *store.Submission) error {
+	if !hasMissingTaskIds(submission.GlobusTaskIds) {
+		return nil
+	}
+
+	for offset := 0; offset < submissionRecoveryScanLimit; offset += submissionRecoveryPageSize {
+		taskList, err := tp.globusClient.TransferGetTaskList(uint(offset), submissionRecoveryPageSize)
+		if err != nil {
+			return err
+		}
+		for _, task := range taskList.Data {
+			match := submissionLabelRegexp.FindStringSubmatch(task.Label)
+			if match == nil || match[1] != submission.ScicatJobId {
+				continue
+			}
+			datasetIndex, err := strconv.Atoi(match[2])
+			if err != nil {
+				continue
+			}
+			i := datasetIndex
+			if submission.IsRetry() {
+				// the tasks of the previous attempts have the same label
+				i = slices.Index(submission.RetriedDatasets, datasetIndex)
+				if i < 0 || isPreviousTask(submission.PreviousDatasets[datasetIndex], task.TaskId) {
+					continue
+				}
+			}
+			if i < len(submission.GlobusTaskIds) && submission.GlobusTaskIds[i] == "" {
+				submission.GlobusTaskIds[i] = task.TaskId
+			}
+		}
+		if !hasMissingTaskIds(submission.GlobusTaskIds) || len(taskList.Data) < submissionRecoveryPageSize {
+			return nil
+		}
+	}
+	return nil
+}
+
+// the label of the globus task of a dataset of a job, appended to the label requested for the transfer if any
+func submissionLabel(label *string, jobId string, datasetIndex int) string {
+	suffix := fmt.Sprintf(submissionLabelFormat, jobId, datasetIndex)
+	if label == nil || *label == "" {
+		return suffix
+	}
+	// the length of globus labels is limited in characters, so the label is measured and cut in runes
+	prefix := []rune(*label)
+	if maxLength := maxGlobusLabelLength - utf8.RuneCountInString(suffix) - utf8.RuneCountInString(" - "); len(prefix) > maxLength {
+		prefix = prefix[:max(maxLength, 0)]
+	}
+	return string(prefix) + " - " + suffix
+}
+
+// finds the scicat job created by a submission
+func findSubmissionJob(scicatUrl string, scicatToken string, submissionId string) (jobs.ScicatJob, bool, error) {
+	filter, err := json.Marshal(map[string]any{
+		"where": map[string]any{
+			"$and": []map[string]any{
+				{"type": jobs.GlobusTransferJobType},
+				{"jobParams.submissionId": submissionId},
+			},
+		},
+	})
+	if err != nil {
+		return jobs.ScicatJob{}, false, err
+	}
+	jobList, err := jobs.GetJobList(scicatUrl, scicatToken, string(filter))
+	if err != nil || len(jobList) == 0 {
+		return jobs.ScicatJob{}, false, err
+	}
+	return jobList[0], true, nil
+}
+
+func isPreviousTask(previous jobs.DatasetTransfer, globusTaskId string) bool {
+	return previous.GlobusTaskId == globusTaskId || slices.Contains(previous.PreviousGlobusTaskIds, globusTaskId)
+}
+
+func hasMissingTaskIds(globusTaskIds []string) bool {
+	for _, globusTaskId := range globusTaskIds {
+		if globusTaskId == "" {
+			return true
+		}
+	}
+	return false
+}
@@ -4,83 +4,175 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/SwissOpenEM/globus"
+	"github.com/SwissOpenEM/globus-transfer-service/internal/metrics"
 	"github.com/SwissOpenEM/globus-transfer-service/internal/serviceuser"
 	"github.com/SwissOpenEM/globus-transfer-service/jobs"
 	"github.com/paulscherrerinstitute/scicat-cli/v3/datasetIngestor"
 )
 
 type transferTask struct {
-	scicatUrl         *string
-	globusClient      globus.GlobusClient
-	scicatServiceUser serviceuser.ScicatServiceUser
-	globusTaskId      string
-	datasetPid        string
-	scicatJobId       string
-	taskPollInterval  time.Duration
-	cancel            chan struct{}
-	cleanup           func()
+	scicatUrl          *string
+	globusClient       globus.GlobusClient
+	scicatServiceUser  serviceuser.ScicatServiceUser
+	scicatJobId        string
+	taskPollInterval   time.Duration
+	stalledGracePeriod time.Duration
+	// the number of consecutive transient errors after which the task fails, and how many occured so far
+	maxConsecutiveErrors int
+	consecutiveErrors    int
+	setStatus            func(jobs.JobResultObject)
+	cleanup              func()
+	markFilesReady       bool
+	metrics              *metrics.Metrics
+	priority             jobs.Priority
+	ownerUser            string
+	ownerGroup           string
+	sourceFacility       string
+	destFacility         string
+	deadline             *time.Time
+	// set while the last status of the task couldn't be reported to scicat
+	unreported bool
+	// the globus tasks listed by the tracker for the current poll
+	listedTasks map[string]globus.Task
 
-	// current status
-	bytesTransferred uint
-	filesTransferred uint
-	filesTotal       uint
+	// current status, one entry (and globus task) per dataset
+	datasets []jobs.DatasetTransfer
 }
 
-func (t transferTask) execute() {
-	defer t.cleanup()
+// poll handles a single poll of the task by the tracker, using the globus tasks it listed if they include the ones
+// of the task. Returns when the task has to be polled next, or false once it has ended and isn't tracked anymore.
+func (t *transferTask) poll(listedTasks map[string]globus.Task, cancelled bool) (time.Time, bool) {
+	t.listedTasks = listedTasks
+	defer func() { t.listedTasks = nil }()
 
-	completed := false
-	var err error = nil
-
-	for {
-		select {
-		case <-t.cancel:
-			_ = t.cancelTask()
-			return
-		default:
+	if cancelled {
+		_ = t.cancelTask()
+		return time.Time{}, false
+	}
+	if t.deadline != nil && time.Now().After(*t.deadline) {
+		if t.timeoutTask() == nil {
+			t.finishTask()
 		}
-		completed, err = t.updateTask()
-		if completed || err != nil {
-			break
+		return time.Time{}, false
+	}
+
+	completed, err := t.updateTask()
+	if err == nil {
+		if completed {
+			t.finishTask()
+			return time.Time{}, false
 		}
-		time.Sleep(t.taskPollInterval)
+		t.consecutiveErrors = 0
+		return time.Now().Add(t.taskPollInterval), true
 	}
 
-	if !completed || err != nil {
-		return // if not completed or error'd, don't mark the dataset as archivable
+	t.consecutiveErrors++
+	if !isTransientError(err) || t.consecutiveErrors > t.maxConsecutiveErrors {
+		t.failTask(err)
+		return time.Time{}, false // if error'd, don't mark the datasets as archivable
 	}
-	t.finishTask()
+	delay := backoffDelay(t.consecutiveErrors)
+	log.Printf("'%s' scicat job - transient error (%d of %d), retrying in %s: %s\n", t.scicatJobId, t.consecutiveErrors, t.maxConsecutiveErrors, delay.Round(time.Millisecond), err.Error())
+	return time.Now().Add(delay), true
 }
 
-func (t transferTask) updateTask() (bool, error) {
-	bytesTransferred, filesTransferred, totalFiles, completed, err := checkTransfer(t.globusClient, t.globusTaskId)
+// the globus tasks of the datasets that are still being transferred
+func (t *transferTask) activeGlobusTaskIds() []string {
+	globusTaskIds := []string{}
+	for _, dataset := range t.datasets {
+		if dataset.Status.IsActive() {
+			globusTaskIds = append(globusTaskIds, dataset.GlobusTaskId)
+		}
+	}
+	return globusTaskIds
+}
 
-	status := jobs.Transferring
-	statusCode := "002"
-	statusMessage := "transferring"
-	errMsg := ""
+// returns the globus task as listed by the tracker for the current poll, or fetches it if it wasn't listed
+func (t *transferTask) getGlobusTask(globusTaskId string) (globus.Task, error) {
+	if globusTask, ok := t.listedTasks[globusTaskId]; ok {
+		return globusTask, nil
+	}
+	return t.globusClient.TransferGetTaskByID(globusTaskId)
+}
+
+// reports the last status of the task to scicat if it couldn't be updated, when the pool shuts down. The job stays unfinished in scicat, to be resumed by the next start of the service.
+func (t *transferTask) flushStatus() {
+	if !t.unreported {
+		return
+	}
+	jobResult := t.jobResult()
+	statusCode, statusMessage, _ := jobStatusCode(jobResult)
 
+	token, err := t.scicatServiceUser.GetToken()
+	if err == nil {
+		_, err = UpdateGlobusTransferScicatJob(*t.scicatUrl, token, t.scicatJobId, statusCode, statusMessage, jobResult)
+	}
 	if err != nil {
-		status = jobs.Failed
-		statusCode = "998"
-		statusMessage = "an error has occured during task polling, this job is not updated anymore"
-		errMsg = err.Error()
-	} else if completed {
-		status = jobs.Finished
-		statusCode = "003"
-		statusMessage = "finished"
+		log.Printf("'%s' scicat job - can't report the last status of the transfer before shutting down: %s\n", t.scicatJobId, err.Error())
+		return
+	}
+	t.unreported = false
+}
+
+// polls the globus tasks of the datasets that are still being transferred, and updates the scicat job
+// with the result. Returns true once the transfers of all datasets have ended, either successfully or not.
+// Datasets whose task can't be polled because of a transient error keep their status, and the error is returned.
+func (t *transferTask) updateTask() (bool, error) {
+	var pollErr error
+	for i := range t.datasets {
+		dataset := &t.datasets[i]
+		if !dataset.Status.IsActive() {
+			continue
+		}
+
+		bytesTransferred, filesTransferred, totalFiles, completed, stalledReason, err := checkTransfer(t.getGlobusTask, dataset.GlobusTaskId)
+		if err != nil && isTransientError(err) {
+			pollErr = err
+			taskLog(t.scicatJobId, dataset.GlobusTaskId, dataset.Pid, int(dataset.BytesTransferred), int(dataset.FilesTransferred), int(dataset.FilesTotal), dataset.Status, err)
+			continue
+		}
+		if err != nil {
+			dataset.Status = jobs.Failed
+			dataset.Error = err.Error()
+		} else {
+			t.metrics.ObserveTransferProgress(t.sourceFacility, t.destFacility, increase(dataset.BytesTransferred, uint(bytesTransferred)), increase(dataset.FilesTransferred, uint(filesTransferred)))
+			dataset.BytesTransferred = uint(bytesTransferred)
+			dataset.FilesTransferred = uint(filesTransferred)
+			dataset.FilesTotal = uint(totalFiles)
+			switch {
+			case completed:
+				dataset.Status = jobs.Finished
+				dataset.StalledReason, dataset.StalledSince = "", nil
+			case stalledReason != "":
+				t.stallDataset(dataset, stalledReason)
+			default:
+				dataset.Status = jobs.Transferring
+				dataset.StalledReason, dataset.StalledSince = "", nil
+			}
+		}
+		if dataset.Status == jobs.Finished || dataset.Status == jobs.Failed {
+			t.recordFailedFiles(dataset)
+		}
+
+		taskLog(t.scicatJobId, dataset.GlobusTaskId, dataset.Pid, bytesTransferred, filesTransferred, totalFiles, dataset.Status, err)
 	}
 
-	taskLog(t.scicatJobId, t.globusTaskId, t.datasetPid, bytesTransferred, filesTransferred, totalFiles, status, err)
+	jobResult := t.jobResult()
+	statusCode, statusMessage, completed := jobStatusCode(jobResult)
+
+	t.setStatus(jobResult)
 
 	token, err := t.scicatServiceUser.GetToken()
 	if err != nil {
-		errFull := fmt.Errorf("getting token failed, task with scicat job id '%s', dataset pid '%s', globus id '%s' cannot be updated: %s", t.scicatJobId, t.datasetPid, t.globusTaskId, err.Error())
+		t.unreported = true
+		errFull := fmt.Errorf("getting token failed, task with scicat job id '%s' cannot be updated: %w", t.scicatJobId, err)
 		log.Println(errFull.Error())
-		return false, errFull
+		// the service user is shared by all tasks, so its login is retried rather than failing the task
+		return false, &transientError{errFull}
 	}
 
 	_, err = UpdateGlobusTransferScicatJob(
@@ -89,63 +181,245 @@ func (t transferTask) updateTask() (bool, error) {
 		t.scicatJobId,
 		statusCode,
 		statusMessage,
-		jobs.JobResultObject{
-			GlobusTaskId:     t.globusTaskId,
-			BytesTransferred: uint(bytesTransferred),
-			FilesTransferred: uint(filesTransferred),
-			FilesTotal:       uint(totalFiles),
-			Status:           status,
-			Error:            errMsg,
-		},
+		jobResult,
 	)
-
+	t.unreported = err != nil
+	if err == nil && pollErr != nil {
+		return false, pollErr
+	}
 	return completed, err
 }
 
-func (t transferTask) finishTask() {
-	token, _ := t.scicatServiceUser.GetToken()
-	err := datasetIngestor.MarkFilesReady(http.DefaultClient, *t.scicatUrl+"api/v3", t.datasetPid, map[string]string{"accessToken": token})
-	if err != nil {
-		errMsg := err.Error()
+// the status code and message of a job with the given result, and whether its transfers have ended
+func jobStatusCode(jobResult jobs.JobResultObject) (string, string, bool) {
+	switch jobResult.Status {
+	case jobs.Stalled:
+		return "004", StalledMessage(jobResult.Datasets), false
+	case jobs.Failed:
+		return "998", "an error has occured during task polling, this job is not updated anymore", true
+	case jobs.Finished:
+		return "003", "finished", true
+	case jobs.Cancelled:
+		return "003", "cancelled", true
+	default:
+		return "002", "transferring", false
+	}
+}
+
+// fails the transfers that are still active after an error the task can't recover from, and reports
+// it to scicat if that's still possible. The globus tasks are left as they are.
+func (t *transferTask) failTask(err error) {
+	for i := range t.datasets {
+		dataset := &t.datasets[i]
+		if !dataset.Status.IsActive() {
+			continue
+		}
+		dataset.Status = jobs.Failed
+		dataset.Error = "the transfer can't be tracked anymore: " + err.Error()
+		dataset.StalledReason, dataset.StalledSince = "", nil
+	}
 
+	jobResult := t.jobResult()
+	t.setStatus(jobResult)
+
+	token, err := t.scicatServiceUser.GetToken()
+	if err == nil {
 		_, err = UpdateGlobusTransferScicatJob(
 			*t.scicatUrl,
 			token,
 			t.scicatJobId,
-			"997",
-			"completed but can't mark dataset as archivable",
-			jobs.JobResultObject{
-				GlobusTaskId:     t.globusTaskId,
-				BytesTransferred: t.bytesTransferred,
-				FilesTransferred: t.filesTransferred,
-				FilesTotal:       t.filesTotal,
-				Status:           jobs.Finished,
-				Error:            errMsg,
-			},
+			"998",
+			"an error has occured during task polling, this job is not updated anymore",
+			jobResult,
 		)
+	}
+	taskLog(t.scicatJobId, jobResult.GlobusTaskId, "", int(jobResult.BytesTransferred), int(jobResult.FilesTransferred), int(jobResult.FilesTotal), jobResult.Status, err)
+}
+
+func (t *transferTask) jobResult() jobs.JobResultObject {
+	jobResult := NewJobResult(t.datasets)
+	jobResult.Deadline = t.deadline
+	return jobResult
+}
+
+// NewJobResult aggregates the state of the transfers of all datasets into a job's result object. The job
+// is transferring while any of its datasets is, and failed once all ended if any of them failed.
+func NewJobResult(datasets []jobs.DatasetTransfer) jobs.JobResultObject {
+	jobResult := jobs.JobResultObject{
+		Datasets: make([]jobs.DatasetTransfer, len(datasets)),
+	}
+	copy(jobResult.Datasets, datasets)
+	if len(datasets) == 1 {
+		jobResult.GlobusTaskId = datasets[0].GlobusTaskId
+		jobResult.PreviousGlobusTaskIds = datasets[0].PreviousGlobusTaskIds
+	}
+
+	active, stalled, failed, cancelled := false, false, false, false
+	errMsgs := []string{}
+	for _, dataset := range datasets {
+		jobResult.BytesTransferred += dataset.BytesTransferred
+		jobResult.FilesTransferred += dataset.FilesTransferred
+		jobResult.FilesTotal += dataset.FilesTotal
+		jobResult.FilesFailed += dataset.FilesFailed
+		switch dataset.Status {
+		case jobs.Waiting, jobs.Transferring:
+			active = true
+		case jobs.Stalled:
+			active, stalled = true, true
+		case jobs.Cancelled:
+			cancelled = true
+		case jobs.Failed:
+			failed = true
+			errMsgs = append(errMsgs, fmt.Sprintf("'%s': %s", dataset.Pid, dataset.Error))
+		}
+	}
+
+	switch {
+	case stalled:
+		jobResult.Status = jobs.Stalled
+	case active:
+		jobResult.Status = jobs.Transferring
+	case failed:
+		jobResult.Status = jobs.Failed
+	case cancelled:
+		jobResult.Status = jobs.Cancelled
+	default:
+		jobResult.Status = jobs.Finished
+	}
+
+	if jobResult.Status == jobs.Failed {
+		if len(datasets) == 1 {
+			jobResult.Error = datasets[0].Error
+		} else {
+			jobResult.Error = "transfer failed for the following datasets - " + strings.Join(errMsgs, ", ")
+		}
+	}
+	return jobResult
+}
+
+// marks the transfer of a dataset as stalled, and fails it once it was stalled for longer than the grace period
+func (t *transferTask) stallDataset(dataset *jobs.DatasetTransfer, reason string) {
+	if dataset.StalledSince == nil {
+		now := time.Now()
+		dataset.StalledSince = &now
+	}
+	dataset.Status = jobs.Stalled
+	dataset.StalledReason = reason
+	if time.Since(*dataset.StalledSince) < t.stalledGracePeriod {
+		return
+	}
+
+	// globus could resume the task later on, which shouldn't happen once its job failed
+	_, _ = t.globusClient.TransferCancelTaskByID(dataset.GlobusTaskId)
+	dataset.Status = jobs.Failed
+	dataset.Error = fmt.Sprintf("globus: the transfer was stalled for longer than %s - %s", t.stalledGracePeriod, reason)
+	dataset.StalledReason, dataset.StalledSince = "", nil
+}
+
+// StalledMessage describes why the transfers of the stalled datasets don't progress
+func StalledMessage(datasets []jobs.DatasetTransfer) string {
+	if len(datasets) == 1 {
+		return "stalled - " + datasets[0].StalledReason
+	}
+	reasons := []string{}
+	for _, dataset := range datasets {
+		if dataset.Status == jobs.Stalled {
+			reasons = append(reasons, fmt.Sprintf("'%s': %s", dataset.Pid, dataset.StalledReason))
+		}
+	}
+	return "stalled - " + strings.Join(reasons, ", ")
+}
+
+// stores the files that globus skipped because of errors in the state of the dataset, once its transfer ended
+func (t *transferTask) recordFailedFiles(dataset *jobs.DatasetTransfer) {
+	skipped, err := SkippedFiles(t.globusClient, dataset.GlobusTaskId)
+	if err != nil {
+		log.Printf("'%s' scicat job, '%s' globus task for '%s' dataset - can't fetch the files skipped because of errors: %s\n", t.scicatJobId, dataset.GlobusTaskId, dataset.Pid, err.Error())
+		return
+	}
+
+	dataset.FilesFailed = uint(len(skipped))
+	dataset.FailedFiles = make([]jobs.FailedFile, 0, min(len(skipped), maxReportedFailedFiles))
+	for _, file := range skipped[:min(len(skipped), maxReportedFailedFiles)] {
+		dataset.FailedFiles = append(dataset.FailedFiles, jobs.FailedFile{
+			Path:         file.SourcePath,
+			ErrorCode:    file.ErrorCode,
+			ErrorDetails: file.ErrorDetails,
+		})
+	}
+}
+
+func (t *transferTask) finishTask() {
+	if !t.markFilesReady {
+		return // retrieved datasets are already archivable
+	}
+	token, _ := t.scicatServiceUser.GetToken()
+
+	errMsgs := []string{}
+	for _, dataset := range t.datasets {
+		if dataset.Status != jobs.Finished {
+			continue
+		}
+		err := datasetIngestor.MarkFilesReady(http.DefaultClient, *t.scicatUrl+"api/v3", dataset.Pid, map[string]string{"accessToken": token})
 		if err != nil {
-			taskLog(t.scicatJobId, t.globusTaskId, t.datasetPid, int(t.bytesTransferred), int(t.filesTransferred), int(t.filesTotal), jobs.Finished, err)
+			errMsgs = append(errMsgs, fmt.Sprintf("'%s': %s", dataset.Pid, err.Error()))
 		}
 	}
+	if len(errMsgs) == 0 {
+		return
+	}
+
+	jobResult := t.jobResult()
+	jobResult.Error = strings.Join(errMsgs, ", ")
+	_, err := UpdateGlobusTransferScicatJob(
+		*t.scicatUrl,
+		token,
+		t.scicatJobId,
+		"997",
+		"completed but can't mark dataset as archivable",
+		jobResult,
+	)
+	if err != nil {
+		taskLog(t.scicatJobId, jobResult.GlobusTaskId, "", int(jobResult.BytesTransferred), int(jobResult.FilesTransferred), int(jobResult.FilesTotal), jobResult.Status, err)
+	}
 }
 
-func (t transferTask) cancelTask() error {
+func (t *transferTask) cancelTask() error {
 	status := jobs.Cancelled
 	statusCode := "003"
 	statusMessage := "cancelled"
-	errMsg := ""
+	errMsgs := []string{}
 
-	_, err := t.globusClient.TransferCancelTaskByID(t.globusTaskId)
-	if err != nil {
-		status = jobs.Failed
+	for i := range t.datasets {
+		dataset := &t.datasets[i]
+		if !dataset.Status.IsActive() {
+			continue
+		}
+		_, err := t.globusClient.TransferCancelTaskByID(dataset.GlobusTaskId)
+		if err != nil {
+			dataset.Status = jobs.Failed
+			dataset.Error = "failed cancelling globus transfer task: " + err.Error()
+			errMsgs = append(errMsgs, dataset.Error)
+			continue
+		}
+		dataset.Status = jobs.Cancelled
+	}
+
+	jobResult := t.jobResult()
+	jobResult.Status = status
+	jobResult.Error = ""
+	if len(errMsgs) > 0 {
+		jobResult.Status = jobs.Failed
 		statusCode = "996"
 		statusMessage = "cancelling failed"
-		errMsg = "failed cancelling globus transfer task: " + err.Error()
+		jobResult.Error = strings.Join(errMsgs, ", ")
 	}
 
+	t.setStatus(jobResult)
+
 	token, err := t.scicatServiceUser.GetToken()
 	if err != nil {
-		taskLog(t.scicatJobId, t.globusTaskId, t.datasetPid, int(t.bytesTransferred), int(t.filesTransferred), int(t.filesTotal), jobs.Cancelled, err)
+		taskLog(t.scicatJobId, jobResult.GlobusTaskId, "", int(jobResult.BytesTransferred), int(jobResult.FilesTransferred), int(jobResult.FilesTotal), jobs.Cancelled, err)
 		return err
 	}
 
@@ -155,44 +429,108 @@ func (t transferTask) cancelTask() error {
 		t.scicatJobId,
 		statusCode,
 		statusMessage,
-		jobs.JobResultObject{
-			GlobusTaskId:     t.globusTaskId,
-			BytesTransferred: t.bytesTransferred,
-			FilesTransferred: t.filesTransferred,
-			FilesTotal:       t.filesTotal,
-			Status:           status,
-			Error:            errMsg,
-		},
+		jobResult,
 	)
 
 	return err
 }
 
-func checkTransfer(client globus.GlobusClient, globusTaskId string) (bytesTransferred int, filesTransferred int, totalFiles int, completed bool, err error) {
-	globusTask, err := client.TransferGetTaskByID(globusTaskId)
+// fails the transfers of the datasets that didn't complete before the deadline of the task, after
+// cancelling their globus tasks
+func (t *transferTask) timeoutTask() error {
+	timeoutMsg := fmt.Sprintf("the transfer didn't complete before its deadline (%s) and was cancelled", t.deadline.Format(time.RFC3339))
+	for i := range t.datasets {
+		dataset := &t.datasets[i]
+		if !dataset.Status.IsActive() {
+			continue
+		}
+
+		// the transfer could have completed since it was last polled
+		bytesTransferred, filesTransferred, totalFiles, completed, _, err := checkTransfer(t.getGlobusTask, dataset.GlobusTaskId)
+		if err == nil && completed {
+			t.metrics.ObserveTransferProgress(t.sourceFacility, t.destFacility, increase(dataset.BytesTransferred, uint(bytesTransferred)), increase(dataset.FilesTransferred, uint(filesTransferred)))
+			dataset.BytesTransferred = uint(bytesTransferred)
+			dataset.FilesTransferred = uint(filesTransferred)
+			dataset.FilesTotal = uint(totalFiles)
+			dataset.Status = jobs.Finished
+			dataset.StalledReason, dataset.StalledSince = "", nil
+			t.recordFailedFiles(dataset)
+			continue
+		}
+
+		// globus fails the task by itself once its deadline passed, so it might not be cancellable anymore
+		_, _ = t.globusClient.TransferCancelTaskByID(dataset.GlobusTaskId)
+		dataset.Status = jobs.Failed
+		dataset.Error = timeoutMsg
+		dataset.StalledReason, dataset.StalledSince = "", nil
+		taskLog(t.scicatJobId, dataset.GlobusTaskId, dataset.Pid, int(dataset.BytesTransferred), int(dataset.FilesTransferred), int(dataset.FilesTotal), dataset.Status, fmt.Errorf("%s", timeoutMsg))
+	}
+
+	jobResult := t.jobResult()
+	t.setStatus(jobResult)
+
+	token, err := t.scicatServiceUser.GetToken()
 	if err != nil {
-		return 0, 0, 1, false, fmt.Errorf("globus: can't continue transfer because an error occured while polling the task \"%s\": %v", globusTaskId, err)
+		taskLog(t.scicatJobId, jobResult.GlobusTaskId, "", int(jobResult.BytesTransferred), int(jobResult.FilesTransferred), int(jobResult.FilesTotal), jobResult.Status, err)
+		return err
+	}
+
+	_, err = UpdateGlobusTransferScicatJob(
+		*t.scicatUrl,
+		token,
+		t.scicatJobId,
+		"995",
+		"timed out",
+		jobResult,
+	)
+	return err
+}
+
+// polls a globus task. A task that is inactive or paused returns the reason why it doesn't progress
+// as stalledReason, as globus might still resume it.
+func checkTransfer(getGlobusTask func(string) (globus.Task, error), globusTaskId string) (bytesTransferred int, filesTransferred int, totalFiles int, completed bool, stalledReason string, err error) {
+	globusTask, err := getGlobusTask(globusTaskId)
+	if err != nil {
+		return 0, 0, 1, false, "", fmt.Errorf("globus: can't continue transfer because an error occured while polling the task \"%s\": %w", globusTaskId, err)
+	}
+	totalFiles = globusTask.Files
+	if globusTask.FilesSkipped != nil {
+		totalFiles -= *globusTask.FilesSkipped
 	}
 	switch globusTask.Status {
 	case "ACTIVE":
-		totalFiles := globusTask.Files
-		if globusTask.FilesSkipped != nil {
-			totalFiles -= *globusTask.FilesSkipped
+		if globusTask.IsPaused {
+			stalledReason = niceStatusReason(globusTask, "the transfer is paused")
 		}
-		return globusTask.BytesTransferred, globusTask.FilesTransferred, totalFiles, false, nil
+		return globusTask.BytesTransferred, globusTask.FilesTransferred, totalFiles, false, stalledReason, nil
 	case "INACTIVE":
-		return 0, 0, 1, false, fmt.Errorf("globus: transfer became inactive, manual intervention required")
+		return globusTask.BytesTransferred, globusTask.FilesTransferred, totalFiles, false, niceStatusReason(globusTask, "the transfer became inactive"), nil
 	case "SUCCEEDED":
-		totalFiles := globusTask.Files
-		if globusTask.FilesSkipped != nil {
-			totalFiles -= *globusTask.FilesSkipped
-		}
-		return globusTask.BytesTransferred, globusTask.FilesTransferred, totalFiles, true, nil
+		return globusTask.BytesTransferred, globusTask.FilesTransferred, totalFiles, true, "", nil
 	case "FAILED":
-		return 0, 0, 1, false, fmt.Errorf("globus: task failed with the following error - code: \"%s\" description: \"%s\"", globusTask.FatalError.Code, globusTask.FatalError.Description)
+		return 0, 0, 1, false, "", fmt.Errorf("globus: task failed with the following error - code: \"%s\" description: \"%s\"", globusTask.FatalError.Code, globusTask.FatalError.Description)
 	default:
-		return 0, 0, 1, false, fmt.Errorf("globus: unknown task status: %s", globusTask.Status)
+		return 0, 0, 1, false, "", fmt.Errorf("globus: unknown task status: %s", globusTask.Status)
+	}
+}
+
+// the reason globus gives for the current state of a task, or the fallback if it gives none
+func niceStatusReason(globusTask globus.Task, fallback string) string {
+	if globusTask.NiceStatus == nil || *globusTask.NiceStatus == "" || *globusTask.NiceStatus == "OK" || *globusTask.NiceStatus == "Queued" {
+		return fallback
+	}
+	if globusTask.NiceStatusShortDescription == "" {
+		return fmt.Sprintf("%s (%s)", fallback, *globusTask.NiceStatus)
+	}
+	return fmt.Sprintf("%s (%s: %s)", fallback, *globusTask.NiceStatus, globusTask.NiceStatusShortDescription)
+}
+
+// the amount by which a counter reported by globus increased since it was last polled
+func increase(previous uint, current uint) uint {
+	if current < previous {
+		return 0
 	}
+	return current - previous
 }
 
 func taskLog(sciacatJobId string, globusTaskId string, datasetPid string, bytesTransferred int, filesTransferred int, totalFiles int, status jobs.JobStatus, err error) {
@@ -0,0 +1,126 @@
+package tasks
+
+import (
+	"log"
+	"slices"
+	"time"
+
+	"github.com/SwissOpenEM/globus"
+)
+
+// how often the tracker looks for the tracked transfers that are due for a poll
+const trackerTickInterval = time.Second
+
+// the globus tasks of the polls of a tick are fetched through the task list of the service account when there
+// are at least that many of them, otherwise one by one. The task list only reaches back to the most recent tasks,
+// older ones are fetched one by one.
+const (
+	minListedPolls       = 10
+	taskListPageSize     = 100
+	taskListMaxTaskCount = 1000
+)
+
+// trackedTransfer is the state of a job's task held by the tracker. All its fields but the task are guarded
+// by the tracked mutex of the pool.
+type trackedTransfer struct {
+	task     *transferTask
+	nextPoll time.Time
+	// set while a worker processes a poll of the task
+	busy            bool
+	cancelRequested bool
+}
+
+// track runs the tracker until the pool shuts down. On every tick, the globus tasks of the jobs that are due for a
+// poll are fetched, ideally all at once, and the polls are dispatched to the workers of the pool. This way the
+// number of goroutines doesn't grow with the number of tracked transfers.
+func (tp TaskPool) track() {
+	defer close(tp.trackerStopped)
+	ticker := time.NewTicker(trackerTickInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-tp.shutdown:
+			return
+		case <-ticker.C:
+			tp.pollDueTasks()
+			tp.dispatchQueued()
+		}
+	}
+}
+
+func (tp TaskPool) pollDueTasks() {
+	now := time.Now()
+	due := []*trackedTransfer{}
+	tp.trackedMutex.Lock()
+	for _, tracked := range tp.tracked {
+		if !tracked.busy && !now.Before(tracked.nextPoll) {
+			tracked.busy = true
+			due = append(due, tracked)
+		}
+	}
+	tp.trackedMutex.Unlock()
+	if len(due) == 0 {
+		return
+	}
+
+	globusTasks := map[string]globus.Task{}
+	if len(due) >= minListedPolls {
+		globusTasks = tp.listGlobusTasks(due)
+	}
+	// the workers take the polls in order, so the ones of the jobs of higher priority are processed first
+	slices.SortStableFunc(due, func(a *trackedTransfer, b *trackedTransfer) int {
+		return b.task.priority.Level() - a.task.priority.Level()
+	})
+	for _, tracked := range due {
+		tp.pool.Submit(func() {
+			tp.pollTask(tracked, globusTasks)
+		})
+	}
+}
+
+// processes a poll of the task, then either schedules its next poll or stops tracking it
+func (tp TaskPool) pollTask(tracked *trackedTransfer, globusTasks map[string]globus.Task) {
+	tp.trackedMutex.Lock()
+	cancelled := tracked.cancelRequested
+	tp.trackedMutex.Unlock()
+
+	nextPoll, ongoing := tracked.task.poll(globusTasks, cancelled)
+	if !ongoing {
+		tracked.task.cleanup()
+		return
+	}
+
+	tp.trackedMutex.Lock()
+	tracked.nextPoll = nextPoll
+	tracked.busy = false
+	tp.trackedMutex.Unlock()
+}
+
+// fetches the globus tasks of the active transfers of the jobs from the task list of the service account. The
+// list is searched until all tasks are found or as far back as it goes, tasks that aren't found are left out.
+func (tp TaskPool) listGlobusTasks(due []*trackedTransfer) map[string]globus.Task {
+	wanted := map[string]bool{}
+	for _, tracked := range due {
+		for _, globusTaskId := range tracked.task.activeGlobusTaskIds() {
+			wanted[globusTaskId] = true
+		}
+	}
+
+	globusTasks := map[string]globus.Task{}
+	for offset := 0; offset < taskListMaxTaskCount && len(globusTasks) < len(wanted); offset += taskListPageSize {
+		taskList, err := tp.globusClient.TransferGetTaskList(uint(offset), taskListPageSize)
+		if err != nil {
+			log.Printf("can't list the globus tasks, the remaining ones are polled one by one: %s\n", err.Error())
+			break
+		}
+		for _, task := range taskList.Data {
+			if wanted[task.TaskId] {
+				globusTasks[task.TaskId] = task
+			}
+		}
+		if len(taskList.Data) < taskListPageSize {
+			break
+		}
+	}
+	return globusTasks
+}
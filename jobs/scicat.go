@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
 )
@@ -13,28 +14,142 @@ import (
 type Dataset struct {
 	Pid   string   `json:"pid"`
 	Files []string `json:"files"`
+	// the subset of files that are symlinks
+	Symlinks []string `json:"symlinks,omitempty"`
+	// the paths the dataset is transferred between, within the collections of the facilities
+	SourcePath      string `json:"sourcePath,omitempty"`
+	DestinationPath string `json:"destinationPath,omitempty"`
+	// the size of the dataset in bytes according to scicat when the transfer was requested
+	Size int64 `json:"size,omitempty"`
 }
 
 type JobParams struct {
-	DatasetList []Dataset `json:"datasetList"`
+	DatasetList         []Dataset `json:"datasetList"`
+	SourceFacility      string    `json:"sourceFacility,omitempty"`
+	DestinationFacility string    `json:"destinationFacility,omitempty"`
+	// set for jobs retrieving datasets that were previously transferred by the service
+	Retrieval bool `json:"retrieval,omitempty"`
+	// the key given by the client to identify the request that created the job
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+	// the options the globus transfers were requested with, not set for jobs created before they were introduced
+	TransferOptions *TransferOptions `json:"transferOptions,omitempty"`
+	// the id of the local submission record the job was created by, which finds the job when recovering it
+	SubmissionId string `json:"submissionId,omitempty"`
+	// the priority the transfer was requested with, normal if not set
+	Priority Priority `json:"priority,omitempty"`
 }
 
+// Priority is the priority with which the transfers of a job are submitted and tracked
+type Priority string
+
+const (
+	PriorityLow    Priority = "low"
+	PriorityNormal Priority = "normal"
+	PriorityHigh   Priority = "high"
+	PriorityUrgent Priority = "urgent"
+)
+
+// the priorities from the lowest to the highest
+var priorities = []Priority{PriorityLow, PriorityNormal, PriorityHigh, PriorityUrgent}
+
+// IsValid returns whether the priority is a known one, or empty
+func (p Priority) IsValid() bool {
+	return p == "" || slices.Contains(priorities, p)
+}
+
+// Level returns the rank of the priority, higher for higher priorities. An empty priority is normal.
+func (p Priority) Level() int {
+	if p == "" {
+		return PriorityNormal.Level()
+	}
+	return slices.Index(priorities, p)
+}
+
+// Raised returns the priority the given number of levels higher, up to the highest one
+func (p Priority) Raised(levels int) Priority {
+	return priorities[min(max(p.Level()+levels, 0), len(priorities)-1)]
+}
+
+// TransferOptions are the globus transfer options of a job, after applying the defaults of its facilities
+type TransferOptions struct {
+	SyncLevel              string `json:"syncLevel,omitempty"`
+	VerifyChecksum         bool   `json:"verifyChecksum"`
+	EncryptData            bool   `json:"encryptData"`
+	PreserveTimestamp      bool   `json:"preserveTimestamp"`
+	SkipSourceErrors       bool   `json:"skipSourceErrors"`
+	FailOnQuotaErrors      bool   `json:"failOnQuotaErrors"`
+	DeleteDestinationExtra bool   `json:"deleteDestinationExtra"`
+	Label                  string `json:"label,omitempty"`
+	// the time after which the transfer is cancelled if it hasn't completed
+	Deadline *time.Time `json:"deadline,omitempty"`
+	// the amount of seconds the transfer can take once submitted to globus, which sets its deadline when it leaves
+	// the queue if none was requested. 0 is unlimited.
+	MaxDuration uint `json:"maxDuration,omitempty"`
+}
+
+const GlobusTransferJobType = "globus_transfer_job"
+
 type JobStatus string
 
 const (
 	Cancelled    JobStatus = "cancelled"
 	Failed       JobStatus = "failed"
 	Finished     JobStatus = "finished"
+	Stalled      JobStatus = "stalled"
+	Submitting   JobStatus = "submitting"
 	Transferring JobStatus = "transferring"
+	Waiting      JobStatus = "waiting"
 )
 
-type JobResultObject struct {
+// IsFinal returns whether the status is one that a job can't leave anymore
+func (s JobStatus) IsFinal() bool {
+	return s == Finished || s == Failed || s == Cancelled
+}
+
+// IsActive returns whether the status is one of a transfer that globus hasn't ended yet
+func (s JobStatus) IsActive() bool {
+	return s == Waiting || s == Transferring || s == Stalled
+}
+
+// DatasetTransfer is the state of the transfer of a single dataset of a job
+type DatasetTransfer struct {
+	Pid              string    `json:"pid"`
 	GlobusTaskId     string    `json:"globusTaskId"`
 	BytesTransferred uint      `json:"bytesTransferred"`
 	FilesTransferred uint      `json:"filesTransferred"`
 	FilesTotal       uint      `json:"filesTotal"`
 	Status           JobStatus `json:"status"`
 	Error            string    `json:"error"`
+	// the globus tasks of the previous attempts at transferring the dataset, oldest first
+	PreviousGlobusTaskIds []string `json:"previousGlobusTaskIds,omitempty"`
+	// the number of files globus skipped because of errors, and the first few of them
+	FilesFailed uint         `json:"filesFailed,omitempty"`
+	FailedFiles []FailedFile `json:"failedFiles,omitempty"`
+	// why globus doesn't progress with the transfer and since when, only set while it's stalled
+	StalledReason string     `json:"stalledReason,omitempty"`
+	StalledSince  *time.Time `json:"stalledSince,omitempty"`
+}
+
+// FailedFile is a file that globus couldn't transfer
+type FailedFile struct {
+	Path         string `json:"path"`
+	ErrorCode    string `json:"errorCode"`
+	ErrorDetails string `json:"errorDetails"`
+}
+
+type JobResultObject struct {
+	GlobusTaskId     string            `json:"globusTaskId"`
+	BytesTransferred uint              `json:"bytesTransferred"`
+	FilesTransferred uint              `json:"filesTransferred"`
+	FilesTotal       uint              `json:"filesTotal"`
+	FilesFailed      uint              `json:"filesFailed,omitempty"`
+	Status           JobStatus         `json:"status"`
+	Error            string            `json:"error"`
+	Datasets         []DatasetTransfer `json:"datasets,omitempty"`
+	// the globus tasks of the previous attempts, only set for jobs of a single dataset
+	PreviousGlobusTaskIds []string `json:"previousGlobusTaskIds,omitempty"`
+	// the time after which the transfer is cancelled if it hasn't completed, renewed when the job is retried
+	Deadline *time.Time `json:"deadline,omitempty"`
 }
 
 type ScicatJob struct {